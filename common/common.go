@@ -1,8 +1,59 @@
 package common
 
 const (
+	// PayBaseURL 微信支付接口地址
+	PayBaseURL = "https://api.mch.weixin.qq.com"
+
+	// PayBackupBaseURL 微信支付容灾接口地址
+	PayBackupBaseURL = "https://api2.mch.weixin.qq.com"
+
 	// UnifiedOrderURL 微信统一下单
-	UnifiedOrderURL = "https://api.mch.weixin.qq.com/pay/unifiedorder"
+	UnifiedOrderURL = PayBaseURL + "/pay/unifiedorder"
+
+	// NativeBizPayURL 扫码支付模式一二维码链接
+	NativeBizPayURL = "weixin://wxpay/bizpayurl"
+
+	// OrderQueryURL 微信查询订单
+	OrderQueryURL = PayBaseURL + "/pay/orderquery"
+
+	// CloseOrderURL 微信关闭订单
+	CloseOrderURL = PayBaseURL + "/pay/closeorder"
+
+	// RefundURL 微信申请退款，需要双向证书
+	RefundURL = PayBaseURL + "/secapi/pay/refund"
+
+	// RefundQueryURL 微信查询退款
+	RefundQueryURL = PayBaseURL + "/pay/refundquery"
+
+	// MicroPayURL 微信付款码支付
+	MicroPayURL = PayBaseURL + "/pay/micropay"
+
+	// ReverseURL 微信撤销订单，需要双向证书
+	ReverseURL = PayBaseURL + "/secapi/pay/reverse"
+
+	// DownloadBillURL 微信下载交易账单
+	DownloadBillURL = PayBaseURL + "/pay/downloadbill"
+
+	// DownloadFundFlowURL 微信下载资金账单，需要双向证书
+	DownloadFundFlowURL = PayBaseURL + "/pay/downloadfundflow"
+
+	// TransfersURL 企业付款到零钱，需要双向证书
+	TransfersURL = PayBaseURL + "/mmpaymkttransfers/promotion/transfers"
+
+	// GetTransferInfoURL 查询企业付款到零钱，需要双向证书
+	GetTransferInfoURL = PayBaseURL + "/mmpaymkttransfers/gettransferinfo"
+
+	// GetPublicKeyURL 获取企业付款到银行卡的RSA加密公钥，需要双向证书
+	GetPublicKeyURL = "https://fraud.mch.weixin.qq.com/risk/getpublickey"
+
+	// PayBankURL 企业付款到银行卡，需要双向证书
+	PayBankURL = PayBaseURL + "/mmpaysptrans/pay_bank"
+
+	// QueryBankURL 查询企业付款到银行卡，需要双向证书
+	QueryBankURL = PayBaseURL + "/mmpaysptrans/query_bank"
+
+	// SandboxSignKeyURL 获取仿真测试验签密钥
+	SandboxSignKeyURL = PayBaseURL + "/sandboxnew/pay/getsignkey"
 )
 
 // https://open.weixin.qq.com/cgi-bin/showdocument?action=dir_list&t=resource/res_list&verify=1&id=open1419317853&token=&lang=zh_CN
@@ -24,5 +75,11 @@ const (
 	JsCode2SessionURL = "https://api.weixin.qq.com/sns/jscode2session"
 
 	// SendRedPackURL 发送现金红包
-	SendRedPackURL = "https://api.mch.weixin.qq.com/mmpaymkttransfers/sendredpack"
+	SendRedPackURL = PayBaseURL + "/mmpaymkttransfers/sendredpack"
+
+	// SendGroupRedPackURL 发送裂变红包
+	SendGroupRedPackURL = PayBaseURL + "/mmpaymkttransfers/sendgroupredpack"
+
+	// GetHBInfoURL 查询红包记录
+	GetHBInfoURL = PayBaseURL + "/mmpaymkttransfers/gethbinfo"
 )
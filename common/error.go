@@ -2,9 +2,29 @@ package common
 
 // 错误的信息
 const (
-	ErrAccessTokenEmpty  = "access token is empty"
-	ErrAppIDEmpty        = "appid empty"
-	ErrRefreshTokenEmpty = "refresh token is empty"
-	ErrOpenIDEmpty       = "openid is empty"
-	ErrCertCertEmpty     = "cert path is empty "
+	ErrAccessTokenEmpty    = "access token is empty"
+	ErrAppIDEmpty          = "appid empty"
+	ErrRefreshTokenEmpty   = "refresh token is empty"
+	ErrOpenIDEmpty         = "openid is empty"
+	ErrCertCertEmpty       = "cert path is empty "
+	ErrTradeNoEmpty        = "transaction_id and out_trade_no are both empty"
+	ErrOutTradeNoEmpty     = "out_trade_no is empty"
+	ErrRefundFeeInvalid    = "refund_fee must be greater than 0 and not exceed total_fee"
+	ErrRefundNoEmpty       = "transaction_id, out_trade_no, out_refund_no and refund_id are all empty"
+	ErrReqInfoEmpty        = "req_info is empty"
+	ErrBillDateEmpty       = "bill_date is empty"
+	ErrProductIDEmpty      = "product_id is empty"
+	ErrAuthCodeEmpty       = "auth_code is empty"
+	ErrSceneInfoEmpty      = "scene_info is empty"
+	ErrGoodsFeeExceed      = "sum of goods price exceeds total_fee"
+	ErrClientIPEmpty       = "client ip is empty"
+	ErrAppIDMismatch       = "appid mismatch"
+	ErrMchIDMismatch       = "mch_id mismatch"
+	ErrSandboxSignKeyEmpty = "sandbox_signkey is empty"
+	ErrAmountInvalid       = "amount must be greater than 0"
+	ErrReUserNameEmpty     = "re_user_name is empty"
+	ErrPartnerTradeNoEmpty = "partner_trade_no is empty"
+	ErrBankCodeEmpty       = "bank_code is empty"
+	ErrBankAccountEmpty    = "bank_no and true_name are required"
+	ErrMchBillNoEmpty      = "mch_billno is empty"
 )
@@ -2,4 +2,4 @@ module github.com/aimuz/wechat-sdk
 
 go 1.13
 
-require github.com/jinzhu/copier v0.0.0-20190924061706-b57f9002281a
+require github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
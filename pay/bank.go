@@ -0,0 +1,274 @@
+package pay
+
+import (
+	"crypto/rsa"
+	"errors"
+	"io/ioutil"
+	"sync"
+
+	"github.com/aimuz/wechat-sdk/common"
+	"github.com/aimuz/wechat-sdk/utils"
+)
+
+// 企业付款到银行卡支持的银行编号
+const (
+	BankCodeICBC  = "1002" // 工商银行
+	BankCodeABC   = "1005" // 农业银行
+	BankCodeBOC   = "1026" // 中国银行
+	BankCodeCCB   = "1003" // 建设银行
+	BankCodeCMB   = "1001" // 招商银行
+	BankCodePSBC  = "1066" // 邮储银行
+	BankCodeBCM   = "1020" // 交通银行
+	BankCodeSPDB  = "1004" // 浦发银行
+	BankCodeCMBC  = "1006" // 民生银行
+	BankCodeCIB   = "1009" // 兴业银行
+	BankCodePAB   = "1010" // 平安银行
+	BankCodeCITIC = "1021" // 中信银行
+	BankCodeHXB   = "1025" // 华夏银行
+	BankCodeCGB   = "1027" // 广发银行
+	BankCodeCEB   = "1022" // 光大银行
+	BankCodeBOB   = "4836" // 北京银行
+	BankCodeNBCB  = "1056" // 宁波银行
+)
+
+// 企业付款到银行卡状态
+const (
+	PayBankStatusProcessing = "PROCESSING" // 处理中
+	PayBankStatusSuccess    = "SUCCESS"    // 付款成功
+	PayBankStatusFailed     = "FAILED"     // 付款失败
+	PayBankStatusBankFail   = "BANK_FAIL"  // 银行退票，订单状态由付款成功流转至退票，退票时付款金额和手续费会自动退还
+)
+
+// bankNames 银行编号对应的银行名称
+var bankNames = map[string]string{
+	BankCodeICBC:  "工商银行",
+	BankCodeABC:   "农业银行",
+	BankCodeBOC:   "中国银行",
+	BankCodeCCB:   "建设银行",
+	BankCodeCMB:   "招商银行",
+	BankCodePSBC:  "邮储银行",
+	BankCodeBCM:   "交通银行",
+	BankCodeSPDB:  "浦发银行",
+	BankCodeCMBC:  "民生银行",
+	BankCodeCIB:   "兴业银行",
+	BankCodePAB:   "平安银行",
+	BankCodeCITIC: "中信银行",
+	BankCodeHXB:   "华夏银行",
+	BankCodeCGB:   "广发银行",
+	BankCodeCEB:   "光大银行",
+	BankCodeBOB:   "北京银行",
+	BankCodeNBCB:  "宁波银行",
+}
+
+type (
+	// GetPublicKeyReq 获取RSA加密公钥请求参数
+	GetPublicKeyReq struct {
+		MchID    string `json:"mch_id"`    // 商户号
+		NonceStr string `json:"nonce_str"` // 随机字符串
+		SignType string `json:"sign_type"` // 签名类型，必须为 MD5
+	}
+
+	// GetPublicKeyResp 获取RSA加密公钥返回
+	GetPublicKeyResp struct {
+		ReturnCode string `xml:"return_code"`
+		ReturnMsg  string `xml:"return_msg"`
+		ResultCode string `xml:"result_code"`
+		ErrCode    string `xml:"err_code"`
+		ErrCodeDes string `xml:"err_code_des"`
+		MchID      string `xml:"mch_id"`
+		PubKey     string `xml:"pub_key"` // PKCS#1 格式的 RSA 公钥
+	}
+
+	// PayBankReq 企业付款到银行卡请求参数
+	PayBankReq struct {
+		MchID          string `json:"mch_id"`           // 商户号，为空时使用 WePay.MchID
+		PartnerTradeNo string `json:"partner_trade_no"` // 商户订单号，为空时自动生成，重试时必须使用原商户订单号
+		NonceStr       string `json:"nonce_str"`        // 随机字符串，为空时自动生成
+		BankNo         string `json:"-"`                // 收款方银行卡号，提交时使用RSA公钥加密
+		TrueName       string `json:"-"`                // 收款方用户名，提交时使用RSA公钥加密
+		EncBankNo      string `json:"enc_bank_no"`      // 加密后的收款方银行卡号，为空时由 BankNo 加密生成
+		EncTrueName    string `json:"enc_true_name"`    // 加密后的收款方用户名，为空时由 TrueName 加密生成
+		BankCode       string `json:"bank_code"`        // 收款方开户行，如 BankCodeICBC
+		Amount         int    `json:"amount"`           // 付款金额，单位为分
+		Desc           string `json:"desc,omitempty"`   // 付款说明
+	}
+
+	// PayBankResp 企业付款到银行卡返回
+	PayBankResp struct {
+		ReturnCode     string `xml:"return_code"`
+		ReturnMsg      string `xml:"return_msg"`
+		ResultCode     string `xml:"result_code"`
+		ErrCode        string `xml:"err_code"`
+		ErrCodeDes     string `xml:"err_code_des"`
+		MchID          string `xml:"mch_id"`
+		NonceStr       string `xml:"nonce_str"`
+		Sign           string `xml:"sign"`
+		PartnerTradeNo string `xml:"partner_trade_no"` // 商户订单号
+		Amount         int    `xml:"amount"`           // 付款金额，单位为分
+		PaymentNo      string `xml:"payment_no"`       // 微信企业付款单号
+		CmmsAmt        int    `xml:"cmms_amt"`         // 手续费金额，单位为分
+	}
+
+	// QueryBankReq 查询企业付款到银行卡请求参数
+	QueryBankReq struct {
+		MchID          string `json:"mch_id"`           // 商户号
+		PartnerTradeNo string `json:"partner_trade_no"` // 商户订单号
+		NonceStr       string `json:"nonce_str"`        // 随机字符串
+	}
+
+	// QueryBankResp 查询企业付款到银行卡返回
+	QueryBankResp struct {
+		ReturnCode     string `xml:"return_code"`
+		ReturnMsg      string `xml:"return_msg"`
+		ResultCode     string `xml:"result_code"`
+		ErrCode        string `xml:"err_code"`
+		ErrCodeDes     string `xml:"err_code_des"`
+		MchID          string `xml:"mch_id"`
+		PartnerTradeNo string `xml:"partner_trade_no"` // 商户订单号
+		PaymentNo      string `xml:"payment_no"`       // 微信企业付款单号
+		BankNoMd5      string `xml:"bank_no_md5"`      // 收款用户银行卡号的 MD5
+		TrueNameMd5    string `xml:"true_name_md5"`    // 收款人真实姓名的 MD5
+		Amount         int    `xml:"amount"`           // 付款金额，单位为分
+		Status         string `xml:"status"`           // 付款状态，如 PayBankStatusSuccess
+		CmmsAmt        int    `xml:"cmms_amt"`         // 手续费金额，单位为分
+		CreateTime     string `xml:"create_time"`      // 商户下单时间
+		PaySuccTime    string `xml:"pay_succ_time"`    // 成功付款时间
+		Reason         string `xml:"reason"`           // 失败原因
+	}
+
+	// bankPublicKey 缓存的企业付款到银行卡RSA公钥
+	bankPublicKey struct {
+		mu  sync.Mutex
+		key *rsa.PublicKey
+	}
+)
+
+// BankName 银行编号对应的银行名称，不支持的银行返回空字符串
+func BankName(bankCode string) string {
+	return bankNames[bankCode]
+}
+
+// GetPublicKey 获取企业付款到银行卡使用的RSA公钥，返回的 PubKey 为 PKCS#1 格式，
+// 可以保存后通过 RSAPublicKeyFile 配置，避免每次启动时获取
+//
+// 需要配置 CertFile、KeyFile、RootCaFile
+func (m *WePay) GetPublicKey() (*GetPublicKeyResp, error) {
+	req := &GetPublicKeyReq{
+		MchID:    m.MchID,
+		NonceStr: utils.RandomString(32),
+		SignType: utils.SignTypeMD5,
+	}
+
+	resp := new(GetPublicKeyResp)
+	_, err := m.mmpayRequest(common.GetPublicKeyURL, req, resp)
+	return resp, err
+}
+
+// BankPublicKey 企业付款到银行卡使用的RSA公钥，优先读取 RSAPublicKeyFile，未配置时通过 GetPublicKey 获取，
+// 首次加载后会缓存
+func (m *WePay) BankPublicKey() (*rsa.PublicKey, error) {
+	m.bankKey.mu.Lock()
+	defer m.bankKey.mu.Unlock()
+
+	if m.bankKey.key != nil {
+		return m.bankKey.key, nil
+	}
+
+	var pemData []byte
+	if m.RSAPublicKeyFile != "" {
+		data, err := ioutil.ReadFile(m.RSAPublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		pemData = data
+	} else {
+		resp, err := m.GetPublicKey()
+		if err != nil {
+			return nil, err
+		}
+		pemData = []byte(resp.PubKey)
+	}
+
+	key, err := utils.ParseRSAPublicKey(pemData)
+	if err != nil {
+		return nil, err
+	}
+
+	m.bankKey.key = key
+	return key, nil
+}
+
+// PayBank 企业付款到银行卡，收款方银行卡号与姓名会使用RSA公钥加密，付款结果需要通过 QueryBank 查询，
+// 返回 ErrSystemError 等结果未明确的错误时，需要使用原商户订单号重试，避免重复付款
+//
+// 需要配置 CertFile、KeyFile、RootCaFile
+func (m *WePay) PayBank(req *PayBankReq) (*PayBankResp, error) {
+	if req.Amount <= 0 {
+		return nil, errors.New(common.ErrAmountInvalid)
+	}
+	if req.BankCode == "" {
+		return nil, errors.New(common.ErrBankCodeEmpty)
+	}
+
+	err := m.encryptBankAccount(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.MchID == "" {
+		req.MchID = m.MchID
+	}
+	if req.NonceStr == "" {
+		req.NonceStr = utils.RandomString(32)
+	}
+	if req.PartnerTradeNo == "" {
+		req.PartnerTradeNo = utils.GetTradeNO(m.MchID)
+	}
+
+	resp := new(PayBankResp)
+	_, err = m.mmpayRequest(common.PayBankURL, req, resp)
+	return resp, err
+}
+
+// encryptBankAccount 使用RSA公钥加密收款方银行卡号与姓名
+func (m *WePay) encryptBankAccount(req *PayBankReq) error {
+	if req.EncBankNo != "" && req.EncTrueName != "" {
+		return nil
+	}
+
+	if req.BankNo == "" || req.TrueName == "" {
+		return errors.New(common.ErrBankAccountEmpty)
+	}
+
+	key, err := m.BankPublicKey()
+	if err != nil {
+		return err
+	}
+
+	req.EncBankNo, err = utils.RSAEncryptOAEP(key, []byte(req.BankNo))
+	if err != nil {
+		return err
+	}
+
+	req.EncTrueName, err = utils.RSAEncryptOAEP(key, []byte(req.TrueName))
+	return err
+}
+
+// QueryBank 查询企业付款到银行卡结果
+//
+// 需要配置 CertFile、KeyFile、RootCaFile
+func (m *WePay) QueryBank(partnerTradeNo string) (*QueryBankResp, error) {
+	if partnerTradeNo == "" {
+		return nil, errors.New(common.ErrPartnerTradeNoEmpty)
+	}
+
+	req := &QueryBankReq{
+		MchID:          m.MchID,
+		PartnerTradeNo: partnerTradeNo,
+		NonceStr:       utils.RandomString(32),
+	}
+
+	resp := new(QueryBankResp)
+	_, err := m.mmpayRequest(common.QueryBankURL, req, resp)
+	return resp, err
+}
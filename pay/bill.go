@@ -0,0 +1,377 @@
+package pay
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/aimuz/wechat-sdk/common"
+	"github.com/aimuz/wechat-sdk/utils"
+)
+
+// 账单类型
+const (
+	BillTypeAll            = "ALL"             // 当日所有订单信息（不含充值退款订单）
+	BillTypeSuccess        = "SUCCESS"         // 当日成功支付的订单（不含充值退款订单）
+	BillTypeRefund         = "REFUND"          // 当日退款订单（不含充值退款订单）
+	BillTypeRechargeRefund = "RECHARGE_REFUND" // 当日充值退款订单
+)
+
+// TarTypeGZIP 账单压缩类型，不填则返回数据流
+const TarTypeGZIP = "GZIP"
+
+type (
+	// DownloadBillReq 下载交易账单请求参数
+	DownloadBillReq struct {
+		AppID    string `json:"appid"`              // 应用ID
+		MchID    string `json:"mch_id"`             // 商户号
+		NonceStr string `json:"nonce_str"`          // 随机字符串
+		BillDate string `json:"bill_date"`          // 对账单日期，格式为yyyyMMdd，如20140603
+		BillType string `json:"bill_type"`          // 账单类型
+		TarType  string `json:"tar_type,omitempty"` // 压缩账单，非必传参数，固定值：GZIP
+	}
+
+	// BillRecord 交易账单明细，金额单位为分，不同账单类型的字段不同，不存在的字段为零值
+	BillRecord struct {
+		TradeTime           string            // 交易时间
+		AppID               string            // 公众账号ID
+		MchID               string            // 商户号
+		SubMchID            string            // 特约商户号
+		DeviceInfo          string            // 设备号
+		TransactionID       string            // 微信订单号
+		OutTradeNo          string            // 商户订单号
+		OpenID              string            // 用户标识
+		TradeType           string            // 交易类型
+		TradeState          string            // 交易状态
+		BankType            string            // 付款银行
+		FeeType             string            // 货币种类
+		SettlementTotalFee  int64             // 应结订单金额
+		CouponFee           int64             // 代金券金额
+		RefundApplyTime     string            // 退款申请时间
+		RefundSuccessTime   string            // 退款成功时间
+		RefundID            string            // 微信退款单号
+		OutRefundNo         string            // 商户退款单号
+		SettlementRefundFee int64             // 退款金额
+		CouponRefundFee     int64             // 充值券退款金额
+		RefundType          string            // 退款类型
+		RefundStatus        string            // 退款状态
+		Body                string            // 商品名称
+		Attach              string            // 商户数据包
+		PoundageFee         int64             // 手续费
+		Rate                string            // 费率
+		TotalFee            int64             // 订单金额
+		RefundFee           int64             // 申请退款金额
+		RateRemark          string            // 费率备注
+		Fields              map[string]string // 原始字段，key 为账单表头
+	}
+
+	// BillSummary 交易账单汇总，金额单位为分
+	BillSummary struct {
+		TotalCount          int64             // 总交易单数
+		SettlementTotalFee  int64             // 应结订单总金额
+		SettlementRefundFee int64             // 退款总金额
+		CouponRefundFee     int64             // 充值券退款总金额
+		PoundageFee         int64             // 手续费总金额
+		TotalFee            int64             // 订单总金额
+		RefundFee           int64             // 申请退款总金额
+		Fields              map[string]string // 原始字段，key 为汇总表头
+	}
+
+	// BillReader 交易账单读取器，逐行解析账单，不会将整个账单读入内存
+	BillReader struct {
+		scanner *billScanner
+		summary *BillSummary
+	}
+)
+
+// DownloadBill 下载交易账单，date 格式为yyyyMMdd，tarType 为空或 TarTypeGZIP
+//
+// 读取完毕后需要调用 Close 关闭
+func (m *WePay) DownloadBill(date, billType, tarType string) (*BillReader, error) {
+	if date == "" {
+		return nil, errors.New(common.ErrBillDateEmpty)
+	}
+	if billType == "" {
+		billType = BillTypeAll
+	}
+
+	req := &DownloadBillReq{
+		AppID:    m.AppID,
+		MchID:    m.MchID,
+		NonceStr: utils.RandomString(32),
+		BillDate: date,
+		BillType: billType,
+		TarType:  tarType,
+	}
+
+	payKey, err := m.payKey()
+	if err != nil {
+		return nil, err
+	}
+
+	// 下载交易账单仅支持 MD5 签名
+	data, err := genSignXML(req, payKey, utils.SignTypeMD5)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := m.newRequest(false, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.ReadCloser
+	err = m.do(common.DownloadBillURL, func(url string) error {
+		body, err = request.NewStreamRequest("POST", url, data)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	scanner, err := newBillScanner(body)
+	if err != nil {
+		return nil, err
+	}
+	return &BillReader{scanner: scanner}, nil
+}
+
+// NewBillReader 解析已下载的交易账单，r 可以是 gzip 压缩的内容
+func NewBillReader(r io.ReadCloser) (*BillReader, error) {
+	scanner, err := newBillScanner(r)
+	if err != nil {
+		return nil, err
+	}
+	return &BillReader{scanner: scanner}, nil
+}
+
+// Next 读取下一条账单明细，读取完毕时返回 io.EOF，此后可以通过 Summary 获取汇总数据
+func (r *BillReader) Next() (*BillRecord, error) {
+	fields, err := r.scanner.next()
+	if err == io.EOF && r.scanner.summary != nil && r.summary == nil {
+		r.summary, err = newBillSummary(r.scanner.summary)
+		if err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newBillRecord(fields)
+}
+
+// Summary 账单汇总，Next 返回 io.EOF 之前为 nil
+func (r *BillReader) Summary() *BillSummary {
+	return r.summary
+}
+
+// Close 关闭账单
+func (r *BillReader) Close() error {
+	return r.scanner.close()
+}
+
+func newBillRecord(fields map[string]string) (*BillRecord, error) {
+	record := &BillRecord{
+		TradeTime:         fields["交易时间"],
+		AppID:             fields["公众账号ID"],
+		MchID:             fields["商户号"],
+		SubMchID:          fields["特约商户号"],
+		DeviceInfo:        fields["设备号"],
+		TransactionID:     fields["微信订单号"],
+		OutTradeNo:        fields["商户订单号"],
+		OpenID:            fields["用户标识"],
+		TradeType:         fields["交易类型"],
+		TradeState:        fields["交易状态"],
+		BankType:          fields["付款银行"],
+		FeeType:           fields["货币种类"],
+		RefundApplyTime:   fields["退款申请时间"],
+		RefundSuccessTime: fields["退款成功时间"],
+		RefundID:          fields["微信退款单号"],
+		OutRefundNo:       fields["商户退款单号"],
+		RefundType:        fields["退款类型"],
+		RefundStatus:      fields["退款状态"],
+		Body:              fields["商品名称"],
+		Attach:            fields["商户数据包"],
+		Rate:              fields["费率"],
+		RateRemark:        fields["费率备注"],
+		Fields:            fields,
+	}
+
+	amounts := map[string]*int64{
+		"应结订单金额":  &record.SettlementTotalFee,
+		"代金券金额":   &record.CouponFee,
+		"退款金额":    &record.SettlementRefundFee,
+		"充值券退款金额": &record.CouponRefundFee,
+		"手续费":     &record.PoundageFee,
+		"订单金额":    &record.TotalFee,
+		"申请退款金额":  &record.RefundFee,
+	}
+	err := parseBillAmounts(fields, amounts)
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func newBillSummary(fields map[string]string) (*BillSummary, error) {
+	summary := &BillSummary{Fields: fields}
+
+	var err error
+	summary.TotalCount, err = parseBillInt(fields["总交易单数"])
+	if err != nil {
+		return nil, err
+	}
+
+	amounts := map[string]*int64{
+		"应结订单总金额":  &summary.SettlementTotalFee,
+		"退款总金额":    &summary.SettlementRefundFee,
+		"充值券退款总金额": &summary.CouponRefundFee,
+		"手续费总金额":   &summary.PoundageFee,
+		"订单总金额":    &summary.TotalFee,
+		"申请退款总金额":  &summary.RefundFee,
+	}
+	err = parseBillAmounts(fields, amounts)
+	if err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
+
+// parseBillAmounts 将账单中以元为单位的金额转换为分
+func parseBillAmounts(fields map[string]string, amounts map[string]*int64) error {
+	for name, amount := range amounts {
+		value := fields[name]
+		if value == "" {
+			continue
+		}
+
+		yuan, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		*amount = int64(math.Round(yuan * 100))
+	}
+	return nil
+}
+
+func parseBillInt(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// billScanner 逐行读取微信账单，账单依次为表头、以 ` 开头的明细行、汇总表头、汇总行
+type billScanner struct {
+	reader  *bufio.Reader
+	closer  io.Closer
+	header  []string
+	summary map[string]string
+}
+
+// newBillScanner 检查下载结果，微信返回 xml 时说明下载失败，返回 gzip 时自动解压
+func newBillScanner(body io.ReadCloser) (*billScanner, error) {
+	reader := bufio.NewReader(body)
+	head, err := reader.Peek(5)
+	if err != nil && err != io.EOF {
+		body.Close()
+		return nil, err
+	}
+
+	if bytes.HasPrefix(head, []byte("<xml>")) {
+		defer body.Close()
+		data, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+
+		params, err := utils.XML2Map(data)
+		if err != nil {
+			return nil, err
+		}
+		return nil, &Error{Code: params["error_code"], Des: params["return_msg"]}
+	}
+
+	if bytes.HasPrefix(head, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			body.Close()
+			return nil, err
+		}
+		reader = bufio.NewReader(gz)
+	}
+
+	return &billScanner{reader: reader, closer: body}, nil
+}
+
+// next 读取下一条明细，key 为表头，读到汇总行时保存到 summary 并返回 io.EOF
+func (s *billScanner) next() (map[string]string, error) {
+	for {
+		line, err := s.readLine()
+		if err != nil {
+			return nil, err
+		}
+		if line == "" {
+			continue
+		}
+
+		if s.header == nil {
+			s.header = splitBillLine(line)
+			continue
+		}
+
+		if !strings.HasPrefix(line, "`") {
+			summaryHeader := splitBillLine(line)
+			values, err := s.readLine()
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+			s.summary = zipBillLine(summaryHeader, splitBillLine(values))
+			return nil, io.EOF
+		}
+
+		return zipBillLine(s.header, splitBillLine(line)), nil
+	}
+}
+
+func (s *billScanner) readLine() (string, error) {
+	line, err := s.reader.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	line = strings.TrimRight(line, "\r\n")
+	return strings.TrimPrefix(line, "\ufeff"), nil
+}
+
+func (s *billScanner) close() error {
+	return s.closer.Close()
+}
+
+// splitBillLine 明细行的每个字段都以 ` 开头，按 ",`" 分割可以避免商品名称中的逗号影响解析
+func splitBillLine(line string) []string {
+	if strings.HasPrefix(line, "`") {
+		return strings.Split(line[1:], ",`")
+	}
+	return strings.Split(line, ",")
+}
+
+func zipBillLine(header, values []string) map[string]string {
+	fields := make(map[string]string, len(header))
+	for i, name := range header {
+		if i < len(values) {
+			fields[name] = strings.TrimSpace(values[i])
+		}
+	}
+	return fields
+}
@@ -0,0 +1,114 @@
+package pay
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// 微信支付文档中的交易账单示例（ALL）
+const billSample = "交易时间,公众账号ID,商户号,特约商户号,设备号,微信订单号,商户订单号,用户标识,交易类型,交易状态,付款银行,货币种类,应结订单金额,代金券金额,微信退款单号,商户退款单号,退款金额,充值券退款金额,退款类型,退款状态,商品名称,商户数据包,手续费,费率,订单金额,申请退款金额,费率备注\n" +
+	"`2014-11-10 16:33:45,`wx2421b1c4370ec43b,`10000100,`0,`1000,`1001690740201411100005734289,`1415640626,`085e9858e3ba5186aafcbaed1,`MICROPAY,`SUCCESS,`OTHERS,`CNY,`0.01,`0.0,`0,`0,`0,`0,`,`,`被扫支付测试,`订单额外描述,`0,`0.60%,`0.01,`0,`\n" +
+	"`2014-11-10 16:46:14,`wx2421b1c4370ec43b,`10000100,`0,`1000,`1002780740201411100005729794,`1415635270,`085e9858e90ca40c0b5aee463,`MICROPAY,`SUCCESS,`OTHERS,`CNY,`1.01,`0.0,`0,`0,`0,`0,`,`,`被扫支付测试,`订单额外描述,`0.01,`0.60%,`1.01,`0,`\n" +
+	"总交易单数,应结订单总金额,退款总金额,充值券退款总金额,手续费总金额,订单总金额,申请退款总金额\n" +
+	"`2,`1.02,`0.0,`0.0,`0.01,`1.02,`0.0\n"
+
+// readBill 读取全部账单明细
+func readBill(body []byte) ([]*BillRecord, *BillSummary, error) {
+	reader, err := NewBillReader(ioutil.NopCloser(bytes.NewReader(body)))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer reader.Close()
+
+	var records []*BillRecord
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			return records, reader.Summary(), nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		records = append(records, record)
+	}
+}
+
+func gzipBytes(t *testing.T, data string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = gz.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestBillReader(t *testing.T) {
+	tests := []struct {
+		name string
+		body []byte
+	}{
+		{"plain", []byte(billSample)},
+		{"bom and crlf", []byte("\ufeff" + strings.Replace(billSample, "\n", "\r\n", -1))},
+		{"gzip", gzipBytes(t, billSample)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, summary, err := readBill(tt.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(records) != 2 {
+				t.Fatalf("got %d records, want 2", len(records))
+			}
+
+			record := records[1]
+			if record.TradeTime != "2014-11-10 16:46:14" || record.TransactionID != "1002780740201411100005729794" ||
+				record.OutTradeNo != "1415635270" || record.TradeType != "MICROPAY" || record.Body != "被扫支付测试" {
+				t.Errorf("record = %+v", record)
+			}
+			if record.SettlementTotalFee != 101 || record.TotalFee != 101 || record.PoundageFee != 1 || record.Rate != "0.60%" {
+				t.Errorf("record amounts = %+v", record)
+			}
+
+			if summary == nil {
+				t.Fatal("summary is nil")
+			}
+			if summary.TotalCount != 2 || summary.SettlementTotalFee != 102 || summary.PoundageFee != 1 || summary.TotalFee != 102 {
+				t.Errorf("summary = %+v", summary)
+			}
+		})
+	}
+}
+
+func TestBillReaderCommaInBody(t *testing.T) {
+	body := strings.Replace(billSample, "`被扫支付测试", "`商品A,商品B", 1)
+	records, _, err := readBill([]byte(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if records[0].Body != "商品A,商品B" || records[0].Attach != "订单额外描述" {
+		t.Errorf("record = %+v", records[0])
+	}
+}
+
+func TestBillReaderError(t *testing.T) {
+	body := "<xml><return_code><![CDATA[FAIL]]></return_code><return_msg><![CDATA[No Bill Exist]]></return_msg><error_code><![CDATA[20002]]></error_code></xml>"
+	_, err := NewBillReader(ioutil.NopCloser(strings.NewReader(body)))
+
+	var payErr *Error
+	if !errors.As(err, &payErr) || payErr.Code != "20002" || payErr.Des != "No Bill Exist" {
+		t.Fatalf("NewBillReader() error = %v", err)
+	}
+}
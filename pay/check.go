@@ -1,6 +1,7 @@
 package pay
 
 import (
+	"crypto/subtle"
 	"strconv"
 
 	"encoding/xml"
@@ -58,6 +59,7 @@ type (
 		CouponCount string   `xml:"coupon_count" json:"coupon_count,omitempty"`   // 代币券使用数量
 
 		Coupon []WxPayNotifyReqCoupon `xml:"-" json:"-"` // 代币券数组
+		Raw    map[string]string      `xml:"-" json:"-"` // 收到的全部原始字段，用于验证签名
 
 		TransactionID string `xml:"transaction_id" json:"transaction_id,omitempty"` // 微信支付ID
 		OutTradeNo    string `xml:"out_trade_no" json:"out_trade_no,omitempty"`     // 商户支付ID
@@ -75,32 +77,68 @@ type (
 	}
 )
 
-//func (m *WxPayNotifyReq) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
-//	m.Coupon = make([]WxPayNotifyReqCoupon, 0)
-//
-//	for {
-//		token, err := d.Token()
-//		if err != nil {
-//			return err
-//		}
-//
-//		switch el := token.(type) {
-//		//case xml.StartElement:
-//		//	if el.Name.Local == "Image" {
-//		//		item := new(Image)
-//		//		if err = d.DecodeElement(item, &el); err != nil {
-//		//			return err
-//		//		}
-//		//		mf.Image = *item
-//		//	}
-//		case xml.EndElement:
-//			if el == start.End() {
-//				return nil
-//			}
-//		}
-//	}
-//	return nil
-//}
+// wxPayNotifyReq 用于避免 UnmarshalXML 递归调用
+type wxPayNotifyReq WxPayNotifyReq
+
+// UnmarshalXML 解析支付通知，保留收到的全部原始字段用于验证签名，
+// 并将 coupon_id_$n、coupon_type_$n、coupon_fee_$n 解析到 Coupon
+func (m *WxPayNotifyReq) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	raw := make(map[string]string)
+	var key string
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch el := token.(type) {
+		case xml.StartElement:
+			key = el.Name.Local
+			raw[key] = ""
+		case xml.CharData:
+			if key != "" {
+				raw[key] += string(el)
+			}
+		case xml.EndElement:
+			if el == start.End() {
+				return m.fromRaw(raw)
+			}
+			key = ""
+		}
+	}
+}
+
+// fromRaw 根据原始字段填充通知内容
+func (m *WxPayNotifyReq) fromRaw(raw map[string]string) error {
+	data, err := utils.Map2XML(raw)
+	if err != nil {
+		return err
+	}
+
+	req := new(wxPayNotifyReq)
+	err = xml.Unmarshal(data, req)
+	if err != nil {
+		return err
+	}
+	*m = WxPayNotifyReq(*req)
+	m.Raw = raw
+
+	// coupon_count 来自尚未验证签名的通知，非法的数量视为 0，并且只解析实际存在的 coupon_id_$n
+	count, _ := strconv.Atoi(raw["coupon_count"])
+	m.Coupon = make([]WxPayNotifyReqCoupon, 0)
+	for i := 0; i < indexedCount(count); i++ {
+		n := strconv.Itoa(i)
+		if _, ok := raw["coupon_id_"+n]; !ok {
+			break
+		}
+		m.Coupon = append(m.Coupon, WxPayNotifyReqCoupon{
+			CouponID:   raw["coupon_id_"+n],
+			CouponType: raw["coupon_type_"+n],
+			CouponFee:  raw["coupon_fee_"+n],
+		})
+	}
+	return nil
+}
 
 /**
  * 微信通知验证
@@ -131,24 +169,40 @@ func WaxVerifyParams(req WaxPayNotifyReq) map[string]string {
 
 // WaxpayVerifySign 微信小程序支付签名验证
 func WaxpayVerifySign(verifyParams map[string]string, signKey string, sign string) bool {
-	signCalc, _ := utils.GenWeChatPaySign(verifyParams, signKey)
-	if sign == signCalc {
-		return true
-	}
-	return false
+	return VerifySign(verifyParams, signKey, sign, utils.SignTypeMD5)
 }
 
-// WxVerifyParams Struct2Map
+// WxVerifyParams 待验证参数，包含收到的全部原始字段，如 coupon_id_$n 等下标字段
 func WxVerifyParams(req *WxPayNotifyReq) map[string]string {
+	if req.Raw != nil {
+		verifyParams := make(map[string]string, len(req.Raw))
+		for k, v := range req.Raw {
+			verifyParams[k] = v
+		}
+		return verifyParams
+	}
+
 	reqmap, _ := utils.Struct2Map(req)
 	return reqmap
 }
 
 // VerifySignMd5 验证签名
 func VerifySignMd5(verifyParams map[string]string, payKey string, sign string) bool {
-	signCalc, _ := utils.GenWeChatPaySign(verifyParams, payKey)
-	if sign == signCalc {
-		return true
+	return VerifySign(verifyParams, payKey, sign, utils.SignTypeMD5)
+}
+
+// VerifySign 按签名类型验证签名，signType 为 "MD5" 或 "HMAC-SHA256"，为空时使用 MD5
+func VerifySign(verifyParams map[string]string, payKey string, sign string, signType string) bool {
+	signer, err := utils.NewSigner(signType)
+	if err != nil {
+		return false
+	}
+
+	signCalc, err := signer(verifyParams, payKey)
+	if err != nil {
+		return false
 	}
-	return false
+
+	// 使用常量时间比较，避免通过响应时间推测签名
+	return subtle.ConstantTimeCompare([]byte(sign), []byte(signCalc)) == 1
 }
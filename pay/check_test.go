@@ -0,0 +1,123 @@
+package pay
+
+import (
+	"encoding/xml"
+	"reflect"
+	"testing"
+
+	"github.com/aimuz/wechat-sdk/utils"
+)
+
+// 微信支付文档中的支付结果通知示例
+func notifySample() map[string]string {
+	return map[string]string{
+		"appid":          "wx2421b1c4370ec43b",
+		"attach":         "支付测试",
+		"bank_type":      "CFT",
+		"fee_type":       "CNY",
+		"is_subscribe":   "Y",
+		"mch_id":         "10000100",
+		"nonce_str":      "5d2b6c2a8db53831f7eda20af46e531c",
+		"openid":         "oUpF8uMEb4qRXf22hE3X68TekukE",
+		"out_trade_no":   "1409811653",
+		"result_code":    "SUCCESS",
+		"return_code":    "SUCCESS",
+		"time_end":       "20140903131540",
+		"total_fee":      "100",
+		"cash_fee":       "100",
+		"trade_type":     "JSAPI",
+		"transaction_id": "1004400740201409030005092168",
+	}
+}
+
+func TestWxPayNotifyReqCoupons(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields map[string]string
+		want   []WxPayNotifyReqCoupon
+	}{
+		{
+			name:   "no coupon",
+			fields: map[string]string{},
+			want:   []WxPayNotifyReqCoupon{},
+		},
+		{
+			name: "two coupons",
+			fields: map[string]string{
+				"cash_fee":      "70",
+				"coupon_fee":    "30",
+				"coupon_count":  "2",
+				"coupon_id_0":   "10000",
+				"coupon_type_0": "CASH",
+				"coupon_fee_0":  "20",
+				"coupon_id_1":   "10001",
+				"coupon_type_1": "NO_CASH",
+				"coupon_fee_1":  "10",
+			},
+			want: []WxPayNotifyReqCoupon{
+				{CouponID: "10000", CouponType: "CASH", CouponFee: "20"},
+				{CouponID: "10001", CouponType: "NO_CASH", CouponFee: "10"},
+			},
+		},
+		{
+			name:   "negative count",
+			fields: map[string]string{"coupon_count": "-1", "coupon_id_0": "10000"},
+			want:   []WxPayNotifyReqCoupon{},
+		},
+		{
+			name:   "huge count",
+			fields: map[string]string{"coupon_count": "99999999999999", "coupon_id_0": "10000", "coupon_fee_0": "20"},
+			want:   []WxPayNotifyReqCoupon{{CouponID: "10000", CouponFee: "20"}},
+		},
+		{
+			name:   "unparsable count",
+			fields: map[string]string{"coupon_count": "abc", "coupon_id_0": "10000"},
+			want:   []WxPayNotifyReqCoupon{},
+		},
+		{
+			name:   "count exceeds fields",
+			fields: map[string]string{"coupon_count": "3", "coupon_id_0": "10000", "coupon_fee_0": "20"},
+			want:   []WxPayNotifyReqCoupon{{CouponID: "10000", CouponFee: "20"}},
+		},
+	}
+
+	payKey := "192006250b4c09247ec02edce69f6a2d"
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := notifySample()
+			for k, v := range tt.fields {
+				params[k] = v
+			}
+
+			var err error
+			params["sign"], err = utils.GenWeChatPaySign(params, payKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			body, err := utils.Map2XML(params)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := new(WxPayNotifyReq)
+			err = xml.Unmarshal(body, req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if req.TransactionID != "1004400740201409030005092168" || req.TotalFee != "100" || req.Attach != "支付测试" {
+				t.Errorf("WxPayNotifyReq = %+v", req)
+			}
+
+			if !reflect.DeepEqual(req.Coupon, tt.want) {
+				t.Errorf("Coupon = %+v, want %+v", req.Coupon, tt.want)
+			}
+
+			// 下标字段也参与签名
+			if !VerifySignMd5(WxVerifyParams(req), payKey, req.Sign) {
+				t.Error("signature of notify with coupons does not verify")
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package pay
+
+import (
+	"errors"
+
+	"github.com/aimuz/wechat-sdk/common"
+	"github.com/aimuz/wechat-sdk/utils"
+)
+
+type (
+	// CloseOrderReq 关闭订单请求参数
+	CloseOrderReq struct {
+		AppID      string `json:"appid"`        // 应用ID
+		MchID      string `json:"mch_id"`       // 商户号
+		OutTradeNo string `json:"out_trade_no"` // 商户订单号
+		NonceStr   string `json:"nonce_str"`    // 随机字符串
+	}
+
+	// CloseOrderResp 关闭订单返回
+	CloseOrderResp struct {
+		ReturnCode string `xml:"return_code"`
+		ReturnMsg  string `xml:"return_msg"`
+		AppID      string `xml:"appid"`
+		MchID      string `xml:"mch_id"`
+		NonceStr   string `xml:"nonce_str"`
+		Sign       string `xml:"sign"`
+		ResultCode string `xml:"result_code"`
+		ResultMsg  string `xml:"result_msg"`
+		ErrCode    string `xml:"err_code"`
+		ErrCodeDes string `xml:"err_code_des"`
+	}
+)
+
+// CloseOrder 关闭订单，订单生成后不能马上调用关单接口，最短调用时间间隔为5分钟
+//
+// 订单已支付时返回 ErrOrderPaid，订单已关闭时返回 ErrOrderClosed，可通过 errors.Is 判断
+func (m *WePay) CloseOrder(outTradeNo string) (*CloseOrderResp, error) {
+	if outTradeNo == "" {
+		return nil, errors.New(common.ErrOutTradeNoEmpty)
+	}
+
+	req := &CloseOrderReq{
+		AppID:      m.AppID,
+		MchID:      m.MchID,
+		OutTradeNo: outTradeNo,
+		NonceStr:   utils.RandomString(32),
+	}
+
+	resp := new(CloseOrderResp)
+	_, err := m.request(common.CloseOrderURL, req, resp)
+	return resp, err
+}
@@ -0,0 +1,56 @@
+package pay
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/aimuz/wechat-sdk/common"
+)
+
+type (
+	// OrderDetail 商品详情，单品优惠活动时使用，提交时会序列化为 json 字符串
+	OrderDetail struct {
+		CostPrice   int           `json:"cost_price,omitempty"` // 订单原价，商户侧一张小票订单可能被分多次支付，订单原价用于记录整张小票的交易金额
+		ReceiptID   string        `json:"receipt_id,omitempty"` // 商家小票ID
+		GoodsDetail []GoodsDetail `json:"goods_detail"`         // 单品列表
+	}
+
+	// GoodsDetail 单品信息
+	GoodsDetail struct {
+		GoodsID      string `json:"goods_id"`                 // 商品编码，由半角的大小写字母、数字、中划线、下划线中的一种或几种组成
+		WxpayGoodsID string `json:"wxpay_goods_id,omitempty"` // 微信支付定义的统一商品编号
+		GoodsName    string `json:"goods_name,omitempty"`     // 商品名称
+		Quantity     int    `json:"quantity"`                 // 商品数量
+		Price        int    `json:"price"`                    // 商品单价，有优惠时为优惠后的单价，单位为分
+	}
+
+	// orderDetail 用于避免 MarshalText 递归调用
+	orderDetail OrderDetail
+)
+
+// MarshalText 序列化为 json 字符串，xml 与 json 编码时都会使用
+func (d OrderDetail) MarshalText() ([]byte, error) {
+	return json.Marshal(orderDetail(d))
+}
+
+// UnmarshalText 从 json 字符串解析
+func (d *OrderDetail) UnmarshalText(data []byte) error {
+	return json.Unmarshal(data, (*orderDetail)(d))
+}
+
+// GoodsFee 单品总金额，即单价与数量乘积之和
+func (d *OrderDetail) GoodsFee() int {
+	fee := 0
+	for _, goods := range d.GoodsDetail {
+		fee += goods.Price * goods.Quantity
+	}
+	return fee
+}
+
+// Check 校验单品总金额不超过订单金额 totalFee，超过时微信会拒绝下单
+func (d *OrderDetail) Check(totalFee int) error {
+	if d.GoodsFee() > totalFee {
+		return errors.New(common.ErrGoodsFeeExceed)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package pay
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrSignMismatch 微信返回结果签名校验失败
+var ErrSignMismatch = errors.New("response sign mismatch")
+
+// Error 微信支付业务错误，对应返回结果中的 err_code 与 err_code_des
+//
+// 可以通过 errors.Is(err, pay.ErrOrderPaid) 判断具体的错误类型
+type Error struct {
+	Code string // 错误代码
+	Des  string // 错误代码描述
+}
+
+// 常见的业务错误，Des 仅作说明，判断时只比较 Code
+var (
+	ErrSystemError        = &Error{Code: "SYSTEMERROR", Des: "系统错误"}
+	ErrOrderPaid          = &Error{Code: "ORDERPAID", Des: "订单已支付"}
+	ErrOrderClosed        = &Error{Code: "ORDERCLOSED", Des: "订单已关闭"}
+	ErrOrderNotExist      = &Error{Code: "ORDERNOTEXIST", Des: "此交易订单号不存在"}
+	ErrSignError          = &Error{Code: "SIGNERROR", Des: "签名错误"}
+	ErrRequirePostMethod  = &Error{Code: "REQUIRE_POST_METHOD", Des: "请使用post方法"}
+	ErrXMLFormatError     = &Error{Code: "XML_FORMAT_ERROR", Des: "XML格式错误"}
+	ErrParamError         = &Error{Code: "PARAM_ERROR", Des: "参数错误"}
+	ErrNotEnough          = &Error{Code: "NOTENOUGH", Des: "余额不足"}
+	ErrOutTradeNoUsed     = &Error{Code: "OUT_TRADE_NO_USED", Des: "商户订单号重复"}
+	ErrAppIDMchIDNotMatch = &Error{Code: "APPID_MCHID_NOT_MATCH", Des: "appid和mch_id不匹配"}
+	ErrBizNeedRetry       = &Error{Code: "BIZERR_NEED_RETRY", Des: "退款业务流程错误，需要商户触发重试来解决"}
+	ErrTradeOverdue       = &Error{Code: "TRADE_OVERDUE", Des: "订单已经超过退款期限"}
+	ErrFrequencyLimited   = &Error{Code: "FREQUENCY_LIMITED", Des: "频率限制"}
+	ErrRefundNotExist     = &Error{Code: "REFUNDNOTEXIST", Des: "退款订单查询失败"}
+	ErrUserPaying         = &Error{Code: "USERPAYING", Des: "用户支付中，需要输入密码"}
+	ErrBankError          = &Error{Code: "BANKERROR", Des: "银行系统异常"}
+	ErrAuthCodeExpire     = &Error{Code: "AUTHCODEEXPIRE", Des: "二维码已过期，请用户在微信上刷新后再试"}
+	ErrAuthCodeInvalid    = &Error{Code: "AUTH_CODE_INVALID", Des: "付款码检验错误"}
+	ErrNoAuth             = &Error{Code: "NO_AUTH", Des: "没有该接口权限"}
+	ErrAmountLimit        = &Error{Code: "AMOUNT_LIMIT", Des: "金额超限"}
+	ErrOpenIDError        = &Error{Code: "OPENID_ERROR", Des: "Openid错误"}
+	ErrSendFailed         = &Error{Code: "SEND_FAILED", Des: "付款错误"}
+	ErrNameMismatch       = &Error{Code: "NAME_MISMATCH", Des: "姓名校验出错"}
+	ErrMoneyLimit         = &Error{Code: "MONEY_LIMIT", Des: "已经达到今日付款总额上限或已达到付款给此用户额度上限"}
+	ErrSendNumLimit       = &Error{Code: "SENDNUM_LIMIT", Des: "该用户今日付款次数超过限制"}
+	ErrV2AccountBan       = &Error{Code: "V2_ACCOUNT_SIMPLE_BAN", Des: "无法给未实名用户付款"}
+	ErrNotFound           = &Error{Code: "NOT_FOUND", Des: "指定单号数据不存在"}
+	ErrFreqLimit          = &Error{Code: "FREQ_LIMIT", Des: "超过频率限制，请稍后再试"}
+)
+
+// Error 实现 error 接口
+func (e *Error) Error() string {
+	return fmt.Sprintf("[%s]%s", e.Code, e.Des)
+}
+
+// Is 错误代码相同即视为同一错误
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	return e.Code == t.Code
+}
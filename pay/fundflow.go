@@ -0,0 +1,197 @@
+package pay
+
+import (
+	"errors"
+	"io"
+
+	"github.com/aimuz/wechat-sdk/common"
+	"github.com/aimuz/wechat-sdk/utils"
+)
+
+// 资金账户类型
+const (
+	AccountTypeBasic     = "Basic"     // 基本账户
+	AccountTypeOperation = "Operation" // 运营账户
+	AccountTypeFees      = "Fees"      // 手续费账户
+)
+
+type (
+	// DownloadFundFlowReq 下载资金账单请求参数，仅支持 HMAC-SHA256 签名
+	DownloadFundFlowReq struct {
+		AppID       string `json:"appid"`              // 应用ID
+		MchID       string `json:"mch_id"`             // 商户号
+		NonceStr    string `json:"nonce_str"`          // 随机字符串
+		SignType    string `json:"sign_type"`          // 签名类型，固定为 HMAC-SHA256
+		BillDate    string `json:"bill_date"`          // 资金账单日期，格式为yyyyMMdd
+		AccountType string `json:"account_type"`       // 资金账户类型
+		TarType     string `json:"tar_type,omitempty"` // 压缩账单，非必传参数，固定值：GZIP
+	}
+
+	// FundFlowRecord 资金账单明细，金额单位为分
+	FundFlowRecord struct {
+		BillingTime      string            // 记账时间
+		BizTransactionID string            // 微信支付业务单号
+		FundFlowID       string            // 资金流水单号
+		BizName          string            // 业务名称
+		BizType          string            // 业务类型
+		FinancialType    string            // 收支类型，收入或支出
+		Amount           int64             // 收支金额
+		Balance          int64             // 账户结余
+		ApplyUser        string            // 资金变更提交申请人
+		Remark           string            // 备注
+		BizVoucherID     string            // 业务凭证号
+		Fields           map[string]string // 原始字段，key 为账单表头
+	}
+
+	// FundFlowSummary 资金账单汇总，金额单位为分
+	FundFlowSummary struct {
+		TotalCount        int64             // 资金流水总笔数
+		IncomeCount       int64             // 收入笔数
+		IncomeAmount      int64             // 收入金额
+		ExpenditureCount  int64             // 支出笔数
+		ExpenditureAmount int64             // 支出金额
+		Fields            map[string]string // 原始字段，key 为汇总表头
+	}
+
+	// FundFlowReader 资金账单读取器，逐行解析账单，不会将整个账单读入内存
+	FundFlowReader struct {
+		scanner *billScanner
+		summary *FundFlowSummary
+	}
+)
+
+// DownloadFundFlow 下载资金账单，date 格式为yyyyMMdd，tarType 为空或 TarTypeGZIP
+//
+// 需要配置 CertFile、KeyFile、RootCaFile，读取完毕后需要调用 Close 关闭
+func (m *WePay) DownloadFundFlow(date, accountType, tarType string) (*FundFlowReader, error) {
+	if date == "" {
+		return nil, errors.New(common.ErrBillDateEmpty)
+	}
+	if accountType == "" {
+		accountType = AccountTypeBasic
+	}
+
+	req := &DownloadFundFlowReq{
+		AppID:       m.AppID,
+		MchID:       m.MchID,
+		NonceStr:    utils.RandomString(32),
+		SignType:    utils.SignTypeHMACSHA256,
+		BillDate:    date,
+		AccountType: accountType,
+		TarType:     tarType,
+	}
+
+	payKey, err := m.payKey()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := genSignXML(req, payKey, utils.SignTypeHMACSHA256)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := m.newRequest(true, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.ReadCloser
+	err = m.do(common.DownloadFundFlowURL, func(url string) error {
+		body, err = request.NewStreamRequest("POST", url, data)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return NewFundFlowReader(body)
+}
+
+// NewFundFlowReader 解析已下载的资金账单，r 可以是 gzip 压缩的内容
+func NewFundFlowReader(r io.ReadCloser) (*FundFlowReader, error) {
+	scanner, err := newBillScanner(r)
+	if err != nil {
+		return nil, err
+	}
+	return &FundFlowReader{scanner: scanner}, nil
+}
+
+// Next 读取下一条资金流水，读取完毕时返回 io.EOF，此后可以通过 Summary 获取汇总数据
+func (r *FundFlowReader) Next() (*FundFlowRecord, error) {
+	fields, err := r.scanner.next()
+	if err == io.EOF && r.scanner.summary != nil && r.summary == nil {
+		r.summary, err = newFundFlowSummary(r.scanner.summary)
+		if err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newFundFlowRecord(fields)
+}
+
+// Summary 账单汇总，Next 返回 io.EOF 之前为 nil
+func (r *FundFlowReader) Summary() *FundFlowSummary {
+	return r.summary
+}
+
+// Close 关闭账单
+func (r *FundFlowReader) Close() error {
+	return r.scanner.close()
+}
+
+func newFundFlowRecord(fields map[string]string) (*FundFlowRecord, error) {
+	record := &FundFlowRecord{
+		BillingTime:      fields["记账时间"],
+		BizTransactionID: fields["微信支付业务单号"],
+		FundFlowID:       fields["资金流水单号"],
+		BizName:          fields["业务名称"],
+		BizType:          fields["业务类型"],
+		FinancialType:    fields["收支类型"],
+		ApplyUser:        fields["资金变更提交申请人"],
+		Remark:           fields["备注"],
+		BizVoucherID:     fields["业务凭证号"],
+		Fields:           fields,
+	}
+
+	amounts := map[string]*int64{
+		"收支金额（元）": &record.Amount,
+		"账户结余（元）": &record.Balance,
+	}
+	err := parseBillAmounts(fields, amounts)
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func newFundFlowSummary(fields map[string]string) (*FundFlowSummary, error) {
+	summary := &FundFlowSummary{Fields: fields}
+
+	var err error
+	summary.TotalCount, err = parseBillInt(fields["资金流水总笔数"])
+	if err != nil {
+		return nil, err
+	}
+	summary.IncomeCount, err = parseBillInt(fields["收入笔数"])
+	if err != nil {
+		return nil, err
+	}
+	summary.ExpenditureCount, err = parseBillInt(fields["支出笔数"])
+	if err != nil {
+		return nil, err
+	}
+
+	amounts := map[string]*int64{
+		"收入金额": &summary.IncomeAmount,
+		"支出金额": &summary.ExpenditureAmount,
+	}
+	err = parseBillAmounts(fields, amounts)
+	if err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
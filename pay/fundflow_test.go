@@ -0,0 +1,66 @@
+package pay
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+// 微信支付文档中的资金账单示例
+const fundFlowSample = "记账时间,微信支付业务单号,资金流水单号,业务名称,业务类型,收支类型,收支金额（元）,账户结余（元）,资金变更提交申请人,备注,业务凭证号\n" +
+	"`2018-02-01 04:21:23,`50000305742018020103387128253,`1900009231201802015884652186,`退款,`退款,`支出,`0.02,`0.17,`system,`缺货,`REF4200000068201801293084726067\n" +
+	"`2018-02-01 10:02:37,`4200000063201802011329101223,`1900009231201802012356985674,`交易,`交易,`收入,`1.00,`1.17,`system,`,`4200000063201802011329101223\n" +
+	"资金流水总笔数,收入笔数,收入金额,支出笔数,支出金额\n" +
+	"`2,`1,`1.00,`1,`0.02\n"
+
+func TestFundFlowReader(t *testing.T) {
+	tests := []struct {
+		name string
+		body []byte
+	}{
+		{"plain", []byte(fundFlowSample)},
+		{"gzip", gzipBytes(t, fundFlowSample)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := NewFundFlowReader(ioutil.NopCloser(bytes.NewReader(tt.body)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer reader.Close()
+
+			var records []*FundFlowRecord
+			for {
+				record, err := reader.Next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				records = append(records, record)
+			}
+
+			if len(records) != 2 {
+				t.Fatalf("got %d records, want 2", len(records))
+			}
+
+			record := records[0]
+			if record.BizName != "退款" || record.FinancialType != "支出" || record.Amount != 2 ||
+				record.Balance != 17 || record.Remark != "缺货" || record.BizVoucherID != "REF4200000068201801293084726067" {
+				t.Errorf("record = %+v", record)
+			}
+
+			summary := reader.Summary()
+			if summary == nil {
+				t.Fatal("summary is nil")
+			}
+			if summary.TotalCount != 2 || summary.IncomeCount != 1 || summary.IncomeAmount != 100 ||
+				summary.ExpenditureCount != 1 || summary.ExpenditureAmount != 2 {
+				t.Errorf("summary = %+v", summary)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package pay
+
+import (
+	"encoding/xml"
+	"errors"
+
+	"github.com/aimuz/wechat-sdk/common"
+	"github.com/aimuz/wechat-sdk/utils"
+)
+
+// AmtTypeAllRand 裂变红包金额设置方式，全部随机
+const AmtTypeAllRand = "ALL_RAND"
+
+// HBBillTypeMCHT 查询红包记录的订单类型，通过商户订单号获取红包信息
+const HBBillTypeMCHT = "MCHT"
+
+// 红包状态
+const (
+	HBStatusSending   = "SENDING"   // 发放中
+	HBStatusSent      = "SENT"      // 已发放待领取
+	HBStatusFailed    = "FAILED"    // 发放失败
+	HBStatusReceived  = "RECEIVED"  // 已领取
+	HBStatusRefunding = "RFUND_ING" // 退款中
+	HBStatusRefund    = "REFUND"    // 已退款
+)
+
+type (
+	// SendGroupRedPackReq 发送裂变红包请求参数，一次可以发放一组红包，首先领取的用户可以分享给好友领取
+	SendGroupRedPackReq struct {
+		XMLName     xml.Name `xml:"xml" json:"-"`
+		NonceStr    string   `xml:"nonce_str,omitempty" json:"nonce_str"`       // NonceStr 随机字符串
+		Sign        string   `xml:"sign,omitempty" json:"sign"`                 // Sign 签名
+		MchBillNo   string   `xml:"mch_billno,omitempty" json:"mch_billno"`     // MchBillNo 商户订单号
+		MchID       string   `xml:"mch_id,omitempty" json:"mch_id"`             // MchID 商户号
+		WxAppID     string   `xml:"wxappid,omitempty" json:"wxappid"`           // WxAppID 公众账号APPID
+		SendName    string   `xml:"send_name,omitempty" json:"send_name"`       // SendName 商户名称，发送者名称
+		ReOpenID    string   `xml:"re_openid,omitempty" json:"re_openid"`       // ReOpenID 种子用户OpenID
+		TotalAmount int64    `xml:"total_amount,omitempty" json:"total_amount"` // TotalAmount 红包发放总金额
+		TotalNum    int      `xml:"total_num,omitempty" json:"total_num"`       // TotalNum 红包发放总人数，3-20
+		AmtType     string   `xml:"amt_type,omitempty" json:"amt_type"`         // AmtType 红包金额设置方式，为空时为 AmtTypeAllRand
+		Wishing     string   `xml:"wishing,omitempty" json:"wishing"`           // Wishing 红包祝福语
+		ActName     string   `xml:"act_name,omitempty" json:"act_name"`         // ActName 活动名称
+		Remark      string   `xml:"remark,omitempty" json:"remark"`             // Remark 备注
+		SceneID     string   `xml:"scene_id,omitempty" json:"scene_id"`         // SceneID 场景ID
+		RiskInfo    string   `xml:"risk_info,omitempty" json:"risk_info"`       // RiskInfo 活动信息
+	}
+
+	// GetHBInfoReq 查询红包记录请求参数
+	GetHBInfoReq struct {
+		NonceStr  string `json:"nonce_str"`  // 随机字符串
+		MchBillNo string `json:"mch_billno"` // 商户订单号
+		MchID     string `json:"mch_id"`     // 商户号
+		AppID     string `json:"appid"`      // 公众账号APPID
+		BillType  string `json:"bill_type"`  // 订单类型，HBBillTypeMCHT
+	}
+
+	// GetHBInfoResp 查询红包记录返回
+	GetHBInfoResp struct {
+		ReturnCode   string   `xml:"return_code"`
+		ReturnMsg    string   `xml:"return_msg"`
+		ResultCode   string   `xml:"result_code"`
+		ErrCode      string   `xml:"err_code"`
+		ErrCodeDes   string   `xml:"err_code_des"`
+		MchBillNo    string   `xml:"mch_billno"`    // 商户订单号
+		MchID        string   `xml:"mch_id"`        // 商户号
+		DetailID     string   `xml:"detail_id"`     // 红包单号
+		Status       string   `xml:"status"`        // 红包状态，如 HBStatusReceived
+		SendType     string   `xml:"send_type"`     // 发放类型，API、UPLOAD 或 ACTIVITY
+		HBType       string   `xml:"hb_type"`       // 红包类型，GROUP 裂变红包，NORMAL 普通红包
+		TotalNum     int      `xml:"total_num"`     // 红包个数
+		TotalAmount  int64    `xml:"total_amount"`  // 红包总金额，单位为分
+		Reason       string   `xml:"reason"`        // 发送失败原因
+		SendTime     string   `xml:"send_time"`     // 红包发送时间
+		RefundTime   string   `xml:"refund_time"`   // 红包退款时间
+		RefundAmount int64    `xml:"refund_amount"` // 红包退款金额
+		Wishing      string   `xml:"wishing"`       // 祝福语
+		Remark       string   `xml:"remark"`        // 活动描述
+		ActName      string   `xml:"act_name"`      // 活动名称
+		HBList       []HBInfo `xml:"hblist>hbinfo"` // 裂变红包的领取列表
+	}
+
+	// HBInfo 红包领取记录
+	HBInfo struct {
+		OpenID  string `xml:"openid"`   // 领取红包的用户openid
+		Amount  int64  `xml:"amount"`   // 领取金额，单位为分
+		RcvTime string `xml:"rcv_time"` // 领取红包的时间
+	}
+)
+
+// SendGroupRedPack 简单调用方法，发送裂变红包，totalNum 为红包发放总人数
+func (m *WePay) SendGroupRedPack(totalAmount int64, totalNum int, openID, sendName, wishing, actName, remark string) (string, *RedPackResp, error) {
+	req := &SendGroupRedPackReq{
+		NonceStr:    utils.RandomString(32),
+		MchBillNo:   utils.GetBillNo(m.MchID, 28),
+		MchID:       m.MchID,
+		WxAppID:     m.AppID,
+		SendName:    sendName,
+		ReOpenID:    openID,
+		TotalAmount: totalAmount,
+		TotalNum:    totalNum,
+		AmtType:     AmtTypeAllRand,
+		Wishing:     wishing,
+		ActName:     actName,
+		Remark:      remark,
+	}
+	return m.SendGroupRedPackByStruct(req)
+}
+
+// SendGroupRedPackByStruct 自定义发送裂变红包参数
+func (m *WePay) SendGroupRedPackByStruct(req *SendGroupRedPackReq) (string, *RedPackResp, error) {
+	resp, err := m.postRedPack(common.SendGroupRedPackURL, req.send)
+	return req.MchBillNo, resp, err
+}
+
+// Send 发送裂变红包
+func (m *SendGroupRedPackReq) Send(payKey string, certFile, keyFile, rootCaFile string) (*RedPackResp, error) {
+	request, err := utils.NewCertRequest(certFile, keyFile, rootCaFile)
+	if err != nil {
+		return nil, err
+	}
+	return m.send(common.SendGroupRedPackURL, payKey, request)
+}
+
+// send 向 url 发送裂变红包
+func (m *SendGroupRedPackReq) send(url, payKey string, request *utils.Request) (*RedPackResp, error) {
+	if m.AmtType == "" {
+		m.AmtType = AmtTypeAllRand
+	}
+
+	tMap, err := utils.Struct2Map(m)
+	if err != nil {
+		return nil, err
+	}
+
+	m.Sign, err = utils.GenWeChatPaySign(tMap, payKey)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := xml.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return postRedPackXML(url, data, payKey, request)
+}
+
+// GetHBInfo 查询红包记录，裂变红包返回每个用户的领取记录，仅支持查询30天内的红包
+//
+// 需要配置 CertFile、KeyFile、RootCaFile
+func (m *WePay) GetHBInfo(mchBillNo string) (*GetHBInfoResp, error) {
+	if mchBillNo == "" {
+		return nil, errors.New(common.ErrMchBillNoEmpty)
+	}
+
+	req := &GetHBInfoReq{
+		NonceStr:  utils.RandomString(32),
+		MchBillNo: mchBillNo,
+		MchID:     m.MchID,
+		AppID:     m.AppID,
+		BillType:  HBBillTypeMCHT,
+	}
+
+	resp := new(GetHBInfoResp)
+	_, err := m.mmpayRequest(common.GetHBInfoURL, req, resp)
+	return resp, err
+}
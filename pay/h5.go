@@ -0,0 +1,65 @@
+package pay
+
+import (
+	"errors"
+	"net/url"
+
+	"github.com/aimuz/wechat-sdk/common"
+	"github.com/aimuz/wechat-sdk/utils"
+)
+
+// TradeTypeMWEB H5 支付交易类型
+const TradeTypeMWEB = "MWEB"
+
+type (
+	// H5UnifiedOrder H5 支付统一下单
+	H5UnifiedOrder struct {
+		UnifiedOrder
+		SceneInfo *SceneInfo `xml:"scene_info,omitempty" json:"scene_info,omitempty"` // 场景信息，H5 支付必须
+	}
+)
+
+// H5Pay H5 支付，用于微信外的手机浏览器，返回支付跳转链接 mweb_url，有效期为5分钟
+//
+// clientIP 必须为用户的真实IP，可以通过 utils.ClientIP 从请求中获取，不能使用服务器IP
+func (m *WePay) H5Pay(totalFee int, clientIP string, h5Info *H5Info, opts ...OrderOption) (mwebURL string, outTradeNo string, err error) {
+	if clientIP == "" {
+		return "", "", errors.New(common.ErrClientIPEmpty)
+	}
+	if h5Info == nil || h5Info.Type == "" {
+		return "", "", errors.New(common.ErrSceneInfoEmpty)
+	}
+
+	outTradeNo = utils.GetTradeNO(m.MchID)
+	h5UnifiedOrder := &H5UnifiedOrder{
+		UnifiedOrder: UnifiedOrder{
+			AppID:      m.AppID,
+			MchID:      m.MchID,
+			NotifyURL:  m.NotifyURL,
+			TradeType:  TradeTypeMWEB,
+			OutTradeNo: outTradeNo,
+			TotalFee:   totalFee,
+			Body:       m.Body,
+			NonceStr:   utils.RandomString(32),
+			SignType:   m.signType(),
+		},
+		SceneInfo: &SceneInfo{H5Info: h5Info},
+	}
+	applyOrderOptions(&h5UnifiedOrder.UnifiedOrder, opts)
+	h5UnifiedOrder.SpBillCreateIP = clientIP
+
+	unifiedOrderResp, err := m.unifiedOrder(h5UnifiedOrder)
+	if err != nil {
+		return "", outTradeNo, err
+	}
+
+	return unifiedOrderResp.MwebURL, outTradeNo, nil
+}
+
+// H5RedirectURL 在 mweb_url 后拼接支付完成后的回跳地址，redirectURL 会进行 urlencode
+func H5RedirectURL(mwebURL, redirectURL string) string {
+	if redirectURL == "" {
+		return mwebURL
+	}
+	return mwebURL + "&redirect_url=" + url.QueryEscape(redirectURL)
+}
@@ -0,0 +1,148 @@
+package pay
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aimuz/wechat-sdk/common"
+	"github.com/aimuz/wechat-sdk/utils"
+)
+
+// defaultFailoverCoolDown 切换到容灾地址后，切换回主地址的默认等待时间
+const defaultFailoverCoolDown = 5 * time.Minute
+
+// defaultTimeout 请求接口的默认超时时间
+const defaultTimeout = 10 * time.Second
+
+// failover 容灾切换状态，主地址失败后在 until 之前使用容灾地址
+type failover struct {
+	mu    sync.Mutex
+	until time.Time
+}
+
+// useBackup 是否使用容灾地址
+func (f *failover) useBackup() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return time.Now().Before(f.until)
+}
+
+// trip 切换到容灾地址，经过 coolDown 后切换回主地址
+func (f *failover) trip(coolDown time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.until = time.Now().Add(coolDown)
+}
+
+// baseURL 接口地址，默认为 common.PayBaseURL
+func (m *WePay) baseURL() string {
+	if m.BaseURL == "" {
+		return common.PayBaseURL
+	}
+	return m.BaseURL
+}
+
+// backupURL 容灾接口地址，未配置 BaseURL 时默认为 common.PayBackupBaseURL，
+// 配置了 BaseURL 而未配置 BackupURL 时不进行容灾切换
+func (m *WePay) backupURL() string {
+	if m.BackupURL == "" && m.BaseURL == "" {
+		return common.PayBackupBaseURL
+	}
+	return m.BackupURL
+}
+
+// coolDown 切换回主地址的等待时间
+func (m *WePay) coolDown() time.Duration {
+	if m.FailoverCoolDown <= 0 {
+		return defaultFailoverCoolDown
+	}
+	return m.FailoverCoolDown
+}
+
+// timeout 请求接口的超时时间
+func (m *WePay) timeout() time.Duration {
+	if m.Timeout <= 0 {
+		return defaultTimeout
+	}
+	return m.Timeout
+}
+
+// newRequest 创建带超时的请求，cert 为 true 时使用双向证书；
+// stream 为 true 时用于下载账单，只限制建立连接与等待响应的时间，不限制读取内容的时间
+func (m *WePay) newRequest(cert, stream bool) (*utils.Request, error) {
+	request := &utils.Request{Client: &http.Client{}}
+	if cert {
+		var err error
+		request, err = utils.NewCertRequest(m.CertFile, m.KeyFile, m.RootCaFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	timeout := m.timeout()
+	if !stream {
+		request.Client.Timeout = timeout
+		return request, nil
+	}
+
+	transport, ok := request.Client.Transport.(*http.Transport)
+	if !ok {
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	transport.DialContext = (&net.Dialer{Timeout: timeout}).DialContext
+	transport.TLSHandshakeTimeout = timeout
+	transport.ResponseHeaderTimeout = timeout
+	request.Client.Transport = transport
+	return request, nil
+}
+
+// isPayURL 是否为 common.PayBaseURL 下的接口地址
+func isPayURL(rawURL string) bool {
+	return strings.HasPrefix(rawURL, common.PayBaseURL+"/")
+}
+
+// apiURL 将 common 中的接口地址替换为 base，仿真测试模式下在路径前加上 /sandboxnew
+func (m *WePay) apiURL(base, rawURL string) string {
+	path := strings.TrimPrefix(rawURL, common.PayBaseURL)
+	if m.Sandbox && !strings.HasPrefix(path, sandboxPathPrefix+"/") {
+		path = sandboxPathPrefix + path
+	}
+	return strings.TrimSuffix(base, "/") + path
+}
+
+// do 使用当前的接口地址调用 send，主地址连接失败或返回 5xx 时切换到容灾地址重试一次，
+// 并在 FailoverCoolDown 内继续使用容灾地址；其他域名的接口地址原样使用
+func (m *WePay) do(rawURL string, send func(url string) error) error {
+	if !isPayURL(rawURL) {
+		return send(rawURL)
+	}
+
+	backup := m.backupURL()
+	if backup == "" {
+		return send(m.apiURL(m.baseURL(), rawURL))
+	}
+
+	if m.failover.useBackup() {
+		return send(m.apiURL(backup, rawURL))
+	}
+
+	err := send(m.apiURL(m.baseURL(), rawURL))
+	if !needFailover(err) {
+		return err
+	}
+
+	m.failover.trip(m.coolDown())
+	return send(m.apiURL(backup, rawURL))
+}
+
+// needFailover 是否需要切换到容灾地址，连接失败、超时或服务端返回 5xx 时需要切换
+func needFailover(err error) bool {
+	switch err.(type) {
+	case *utils.StatusError, net.Error:
+		return true
+	}
+	return false
+}
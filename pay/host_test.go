@@ -0,0 +1,114 @@
+package pay
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aimuz/wechat-sdk/utils"
+)
+
+const testPayKey = "192006250b4c09247ec02edce69f6a2d"
+
+// orderQueryHandler 返回已签名的查询订单成功结果，并记录请求次数
+func orderQueryHandler(t *testing.T, hits *int32) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+		params := map[string]string{
+			"return_code":  "SUCCESS",
+			"result_code":  "SUCCESS",
+			"trade_state":  "SUCCESS",
+			"out_trade_no": "1409811653",
+			"nonce_str":    utils.RandomString(32),
+		}
+		sign, err := utils.GenWeChatPaySign(params, testPayKey)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		params["sign"] = sign
+		data, err := utils.Map2XML(params)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, _ = w.Write(data)
+	}
+}
+
+func newTestWePay(primary, backup string) *WePay {
+	return &WePay{
+		AppID:            "wx2421b1c4370ec43b",
+		MchID:            "10000100",
+		PayKey:           testPayKey,
+		BaseURL:          primary,
+		BackupURL:        backup,
+		FailoverCoolDown: 200 * time.Millisecond,
+		Timeout:          time.Second,
+	}
+}
+
+func TestFailover(t *testing.T) {
+	var primaryHits, backupHits int32
+	var primaryDown int32 = 1
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&primaryDown) == 1 {
+			atomic.AddInt32(&primaryHits, 1)
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		orderQueryHandler(t, &primaryHits)(w, r)
+	}))
+	defer primary.Close()
+	backup := httptest.NewServer(orderQueryHandler(t, &backupHits))
+	defer backup.Close()
+
+	m := newTestWePay(primary.URL, backup.URL)
+	check := func(step string, wantPrimary, wantBackup int32) {
+		t.Helper()
+		if _, err := m.OrderQuery("", "1409811653"); err != nil {
+			t.Fatalf("%s: OrderQuery() error = %v", step, err)
+		}
+		if got := atomic.LoadInt32(&primaryHits); got != wantPrimary {
+			t.Errorf("%s: primary hits = %d, want %d", step, got, wantPrimary)
+		}
+		if got := atomic.LoadInt32(&backupHits); got != wantBackup {
+			t.Errorf("%s: backup hits = %d, want %d", step, got, wantBackup)
+		}
+	}
+
+	// 主地址返回 5xx，切换到容灾地址重试
+	check("trip", 1, 1)
+	// 冷却时间内直接使用容灾地址
+	check("backup", 1, 2)
+
+	// 冷却时间结束后切换回主地址
+	atomic.StoreInt32(&primaryDown, 0)
+	time.Sleep(m.FailoverCoolDown + 50*time.Millisecond)
+	check("recover", 2, 2)
+}
+
+func TestFailoverTimeout(t *testing.T) {
+	var backupHits int32
+	release := make(chan struct{})
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 接受连接后不返回结果，直到测试结束
+		<-release
+	}))
+	defer primary.Close()
+	defer close(release)
+	backup := httptest.NewServer(orderQueryHandler(t, &backupHits))
+	defer backup.Close()
+
+	m := newTestWePay(primary.URL, backup.URL)
+	m.Timeout = 100 * time.Millisecond
+
+	if _, err := m.OrderQuery("", "1409811653"); err != nil {
+		t.Fatalf("OrderQuery() error = %v", err)
+	}
+	if got := atomic.LoadInt32(&backupHits); got != 1 {
+		t.Errorf("backup hits = %d, want 1", got)
+	}
+}
@@ -0,0 +1,74 @@
+package pay
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aimuz/wechat-sdk/common"
+	"github.com/aimuz/wechat-sdk/utils"
+)
+
+// TradeTypeJSAPI 公众号支付交易类型
+const TradeTypeJSAPI = "JSAPI"
+
+type (
+	// JSAPIPayRet 公众号支付返回内容，用于 WeixinJSBridge.invoke('getBrandWCPayRequest') 调起支付
+	JSAPIPayRet struct {
+		AppID     string `json:"appId"`     // 公众号ID
+		TimeStamp string `json:"timeStamp"` // 时间戳
+		NonceStr  string `json:"nonceStr"`  // 随机字符串
+		Package   string `json:"package"`   // 统一下单接口返回的 prepay_id 参数值，提交格式如：prepay_id=*
+		SignType  string `json:"signType"`  // 签名类型，与统一下单的签名类型一致
+		PaySign   string `json:"paySign"`   // 签名
+	}
+)
+
+// JSAPIPay 公众号支付，openID 为用户在公众号下的唯一标识
+func (m *WePay) JSAPIPay(totalFee int, openID string, opts ...OrderOption) (results *JSAPIPayRet, outTradeNo string, err error) {
+	if openID == "" {
+		return nil, "", errors.New(common.ErrOpenIDEmpty)
+	}
+
+	outTradeNo = utils.GetTradeNO(m.MchID)
+	jsapiUnifiedOrder := &WxaUnifiedOrder{
+		UnifiedOrder: UnifiedOrder{
+			AppID:      m.AppID,
+			MchID:      m.MchID,
+			NotifyURL:  m.NotifyURL,
+			TradeType:  TradeTypeJSAPI,
+			OutTradeNo: outTradeNo,
+			TotalFee:   totalFee,
+			Body:       m.Body,
+			NonceStr:   utils.RandomString(32),
+			SignType:   m.signType(),
+		},
+		OpenID: openID,
+	}
+	applyOrderOptions(&jsapiUnifiedOrder.UnifiedOrder, opts)
+
+	unifiedOrderResp, err := m.unifiedOrder(jsapiUnifiedOrder)
+	if err != nil {
+		return results, outTradeNo, err
+	}
+
+	results = &JSAPIPayRet{
+		AppID:     m.AppID,
+		TimeStamp: fmt.Sprintf("%d", time.Now().Unix()),
+		NonceStr:  utils.RandomString(32),
+		Package:   "prepay_id=" + unifiedOrderResp.PrepayID,
+		SignType:  m.signType(),
+	}
+
+	r, err := utils.Struct2Map(results)
+	if err != nil {
+		return results, outTradeNo, err
+	}
+
+	results.PaySign, err = m.sign(r)
+	if err != nil {
+		return results, outTradeNo, err
+	}
+
+	return
+}
@@ -0,0 +1,165 @@
+package pay
+
+import (
+	"errors"
+	"time"
+
+	"github.com/aimuz/wechat-sdk/common"
+	"github.com/aimuz/wechat-sdk/utils"
+)
+
+// 付款码支付结果
+const (
+	MicroPayStatusPaid     = "PAID"     // 支付成功
+	MicroPayStatusFailed   = "FAILED"   // 支付失败
+	MicroPayStatusReversed = "REVERSED" // 支付超时或结果未知，订单已撤销
+)
+
+const (
+	// microPayTimeout 默认等待用户支付的时间
+	microPayTimeout = 30 * time.Second
+	// microPayQueryInterval 用户支付中时查询订单的间隔
+	microPayQueryInterval = 5 * time.Second
+)
+
+type (
+	// MicroPayReq 付款码支付请求参数
+	MicroPayReq struct {
+		AppID          string `json:"appid"`                 // 应用ID
+		MchID          string `json:"mch_id"`                // 商户号
+		DeviceInfo     string `json:"device_info,omitempty"` // 终端设备号
+		NonceStr       string `json:"nonce_str"`             // 随机字符串
+		Body           string `json:"body"`                  // 商品描述
+		Attach         string `json:"attach,omitempty"`      // 附加数据
+		OutTradeNo     string `json:"out_trade_no"`          // 商户订单号
+		TotalFee       int    `json:"total_fee"`             // 订单金额
+		SpBillCreateIP string `json:"spbill_create_ip"`      // 终端IP
+		AuthCode       string `json:"auth_code"`             // 付款码，扫码支付授权码
+	}
+
+	// MicroPayResp 付款码支付返回
+	MicroPayResp struct {
+		ReturnCode    string `xml:"return_code"`
+		ReturnMsg     string `xml:"return_msg"`
+		AppID         string `xml:"appid"`
+		MchID         string `xml:"mch_id"`
+		NonceStr      string `xml:"nonce_str"`
+		Sign          string `xml:"sign"`
+		ResultCode    string `xml:"result_code"`
+		ErrCode       string `xml:"err_code"`
+		ErrCodeDes    string `xml:"err_code_des"`
+		OpenID        string `xml:"openid"`         // 用户标识
+		TradeType     string `xml:"trade_type"`     // 交易类型，MICROPAY
+		BankType      string `xml:"bank_type"`      // 付款银行
+		TotalFee      int    `xml:"total_fee"`      // 订单金额
+		CashFee       int    `xml:"cash_fee"`       // 现金支付金额
+		TransactionID string `xml:"transaction_id"` // 微信支付订单号
+		OutTradeNo    string `xml:"out_trade_no"`   // 商户订单号
+		TimeEnd       string `xml:"time_end"`       // 支付完成时间
+	}
+
+	// MicroPayResult 付款码支付最终结果
+	MicroPayResult struct {
+		Status        string // 支付结果，MicroPayStatusPaid、MicroPayStatusFailed 或 MicroPayStatusReversed
+		OutTradeNo    string // 商户订单号
+		TransactionID string // 微信支付订单号，支付成功时返回
+		Err           error  // 支付失败的原因
+	}
+)
+
+// MicroPay 付款码支付，authCode 为扫描用户付款码得到的授权码
+//
+// 用户支付中（需要输入密码）或支付结果未知时，会在 timeout 内轮询查询订单，
+// 超时仍未支付成功则撤销订单，timeout 为 0 时默认等待30秒。撤销订单需要配置 CertFile、KeyFile、RootCaFile，
+// 未配置时直接返回错误
+//
+// 只有支付结果无法确定（如撤销失败）时才返回 error，此时需要商户自行查询或撤销订单
+func (m *WePay) MicroPay(authCode string, totalFee int, timeout time.Duration) (*MicroPayResult, error) {
+	if authCode == "" {
+		return nil, errors.New(common.ErrAuthCodeEmpty)
+	}
+	// 支付结果未知时需要撤销订单，提前检查证书配置，避免轮询超时后才发现无法撤销
+	if m.CertFile == "" || m.KeyFile == "" || m.RootCaFile == "" {
+		return nil, errors.New(common.ErrCertCertEmpty)
+	}
+	if timeout <= 0 {
+		timeout = microPayTimeout
+	}
+
+	req := &MicroPayReq{
+		AppID:          m.AppID,
+		MchID:          m.MchID,
+		NonceStr:       utils.RandomString(32),
+		Body:           m.Body,
+		OutTradeNo:     utils.GetTradeNO(m.MchID),
+		TotalFee:       totalFee,
+		SpBillCreateIP: utils.LocalIP(),
+		AuthCode:       authCode,
+	}
+	result := &MicroPayResult{OutTradeNo: req.OutTradeNo}
+
+	resp := new(MicroPayResp)
+	_, err := m.request(common.MicroPayURL, req, resp)
+	if err == nil {
+		result.Status = MicroPayStatusPaid
+		result.TransactionID = resp.TransactionID
+		return result, nil
+	}
+
+	// 明确的业务错误，如付款码过期、余额不足，支付失败
+	bizErr, ok := err.(*Error)
+	if ok && !errors.Is(bizErr, ErrUserPaying) && !errors.Is(bizErr, ErrSystemError) && !errors.Is(bizErr, ErrBankError) {
+		result.Status = MicroPayStatusFailed
+		result.Err = err
+		return result, nil
+	}
+
+	// 用户支付中或支付结果未知，轮询查询订单
+	if m.waitMicroPay(result, timeout) {
+		return result, nil
+	}
+
+	// 超时或结果仍然未知，撤销订单
+	_, err = m.Reverse(req.OutTradeNo)
+	if err != nil {
+		return result, err
+	}
+	result.Status = MicroPayStatusReversed
+	return result, nil
+}
+
+// waitMicroPay 轮询查询订单直到支付结果确定或超时，结果确定时返回 true
+func (m *WePay) waitMicroPay(result *MicroPayResult, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			return false
+		}
+		if wait > microPayQueryInterval {
+			wait = microPayQueryInterval
+		}
+		time.Sleep(wait)
+
+		order, err := m.OrderQuery("", result.OutTradeNo)
+		if errors.Is(err, ErrOrderNotExist) {
+			result.Status = MicroPayStatusFailed
+			result.Err = err
+			return true
+		}
+		if err != nil {
+			continue
+		}
+
+		switch order.TradeState {
+		case TradeStateSuccess:
+			result.Status = MicroPayStatusPaid
+			result.TransactionID = order.TransactionID
+			return true
+		case TradeStatePayError, TradeStateRevoked, TradeStateClosed:
+			result.Status = MicroPayStatusFailed
+			result.Err = errors.New(order.TradeStateDesc)
+			return true
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package pay
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/aimuz/wechat-sdk/common"
+	"github.com/aimuz/wechat-sdk/utils"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// TradeTypeNative 扫码支付交易类型
+const TradeTypeNative = "NATIVE"
+
+// maxNotifyBodySize 微信回调请求体的最大长度
+const maxNotifyBodySize = 1 << 20
+
+// nativePrepayFailDes 扫码支付模式一下单失败时展示给用户的提示，避免泄露内部错误
+const nativePrepayFailDes = "下单失败，请稍后再试"
+
+type (
+	// NativeUnifiedOrder 扫码支付统一下单
+	NativeUnifiedOrder struct {
+		UnifiedOrder
+		ProductID string     `xml:"product_id" json:"product_id"`                     // 商品ID，扫码支付必须
+		SceneInfo *SceneInfo `xml:"scene_info,omitempty" json:"scene_info,omitempty"` // 场景信息，如门店信息
+	}
+
+	// NativeCallbackReq 扫码支付模式一回调参数
+	NativeCallbackReq struct {
+		AppID       string `xml:"appid"`        // 应用ID
+		OpenID      string `xml:"openid"`       // 用户标识
+		MchID       string `xml:"mch_id"`       // 商户号
+		IsSubscribe string `xml:"is_subscribe"` // 是否关注公众账号
+		NonceStr    string `xml:"nonce_str"`    // 随机字符串
+		ProductID   string `xml:"product_id"`   // 商品ID
+		Sign        string `xml:"sign"`         // 签名
+	}
+
+	// NativeOrderFunc 根据扫码支付模式一回调创建订单，返回的订单中为空的公共参数会使用 WePay 的配置填充，
+	// trade_type 与 product_id 由回调决定；返回 error 时会将错误信息作为 err_code_des 展示给用户，
+	// 统一下单等内部错误只展示统一的提示
+	NativeOrderFunc func(req *NativeCallbackReq) (*NativeUnifiedOrder, error)
+
+	// NativeCallbackHandler 扫码支付模式一回调处理，验证签名后调用统一下单并返回 prepay_id
+	NativeCallbackHandler struct {
+		pay         *WePay
+		createOrder NativeOrderFunc
+	}
+)
+
+// NativePay 扫码支付模式二，返回二维码链接 code_url，有效期为2小时
+//
+// 可以通过 NativeQRCode 将 code_url 生成二维码图片
+func (m *WePay) NativePay(totalFee int, productID string, opts ...OrderOption) (codeURL string, outTradeNo string, err error) {
+	if productID == "" {
+		return "", "", errors.New(common.ErrProductIDEmpty)
+	}
+
+	outTradeNo = utils.GetTradeNO(m.MchID)
+	nativeUnifiedOrder := &NativeUnifiedOrder{
+		UnifiedOrder: UnifiedOrder{
+			AppID:      m.AppID,
+			MchID:      m.MchID,
+			NotifyURL:  m.NotifyURL,
+			TradeType:  TradeTypeNative,
+			OutTradeNo: outTradeNo,
+			TotalFee:   totalFee,
+			Body:       m.Body,
+			NonceStr:   utils.RandomString(32),
+			SignType:   m.signType(),
+		},
+		ProductID: productID,
+	}
+	applyOrderOptions(&nativeUnifiedOrder.UnifiedOrder, opts)
+
+	unifiedOrderResp, err := m.unifiedOrder(nativeUnifiedOrder)
+	if err != nil {
+		return "", outTradeNo, err
+	}
+
+	return unifiedOrderResp.CodeURL, outTradeNo, nil
+}
+
+// NativeQRCode 将 code_url 生成 PNG 格式的二维码图片，size 为图片边长（像素）
+func NativeQRCode(codeURL string, size int) ([]byte, error) {
+	return qrcode.Encode(codeURL, qrcode.Medium, size)
+}
+
+// NativeBizPayURL 生成扫码支付模式一的二维码链接，每个商品可以生成固定的二维码
+func (m *WePay) NativeBizPayURL(productID string) (string, error) {
+	if productID == "" {
+		return "", errors.New(common.ErrProductIDEmpty)
+	}
+
+	params := map[string]string{
+		"appid":      m.AppID,
+		"mch_id":     m.MchID,
+		"product_id": productID,
+		"time_stamp": strconv.FormatInt(time.Now().Unix(), 10),
+		"nonce_str":  utils.RandomString(32),
+	}
+
+	values := url.Values{}
+	for k, v := range params {
+		values.Set(k, v)
+	}
+
+	payKey, err := m.payKey()
+	if err != nil {
+		return "", err
+	}
+
+	// 模式一二维码仅支持 MD5 签名
+	sign, err := utils.GenWeChatPaySign(params, payKey)
+	if err != nil {
+		return "", err
+	}
+	values.Set("sign", sign)
+
+	return common.NativeBizPayURL + "?" + values.Encode(), nil
+}
+
+// NewNativeCallbackHandler 创建扫码支付模式一回调处理，createOrder 用于根据回调创建订单
+func (m *WePay) NewNativeCallbackHandler(createOrder NativeOrderFunc) *NativeCallbackHandler {
+	return &NativeCallbackHandler{pay: m, createOrder: createOrder}
+}
+
+// ServeHTTP 实现 http.Handler
+func (h *NativeCallbackHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxNotifyBodySize))
+	if err != nil {
+		writeReturnFail(w, err.Error())
+		return
+	}
+
+	params, err := utils.XML2Map(body)
+	if err != nil {
+		writeReturnFail(w, err.Error())
+		return
+	}
+
+	payKey, err := h.pay.payKey()
+	if err != nil {
+		writeReturnFail(w, err.Error())
+		return
+	}
+
+	if !verifyRespSign(params, payKey, utils.SignTypeMD5) {
+		writeReturnFail(w, ErrSignMismatch.Error())
+		return
+	}
+
+	if params["appid"] != h.pay.AppID {
+		writeReturnFail(w, common.ErrAppIDMismatch)
+		return
+	}
+
+	if params["mch_id"] != h.pay.MchID {
+		writeReturnFail(w, common.ErrMchIDMismatch)
+		return
+	}
+
+	req := &NativeCallbackReq{
+		AppID:       params["appid"],
+		OpenID:      params["openid"],
+		MchID:       params["mch_id"],
+		IsSubscribe: params["is_subscribe"],
+		NonceStr:    params["nonce_str"],
+		ProductID:   params["product_id"],
+		Sign:        params["sign"],
+	}
+
+	prepayID, errDes := h.prepay(req)
+	reply := map[string]string{
+		"return_code": "SUCCESS",
+		"appid":       h.pay.AppID,
+		"mch_id":      h.pay.MchID,
+		"nonce_str":   utils.RandomString(32),
+		"prepay_id":   prepayID,
+		"result_code": "SUCCESS",
+	}
+	if errDes != "" {
+		reply["result_code"] = "FAIL"
+		reply["err_code_des"] = errDes
+	}
+
+	reply["sign"], err = utils.GenWeChatPaySign(reply, payKey)
+	if err != nil {
+		writeReturnFail(w, err.Error())
+		return
+	}
+	writeXML(w, reply)
+}
+
+// prepay 创建订单并调用统一下单，失败时返回展示给用户的错误信息，
+// 只有 NativeOrderFunc 返回的错误会原样展示，内部错误使用统一的提示
+func (h *NativeCallbackHandler) prepay(req *NativeCallbackReq) (prepayID string, errDes string) {
+	order, err := h.createOrder(req)
+	if err != nil {
+		return "", err.Error()
+	}
+	if order == nil {
+		return "", nativePrepayFailDes
+	}
+
+	order.TradeType = TradeTypeNative
+	order.ProductID = req.ProductID
+	h.pay.fillUnifiedOrder(&order.UnifiedOrder)
+
+	resp, err := h.pay.unifiedOrder(order)
+	if err != nil {
+		return "", nativePrepayFailDes
+	}
+	return resp.PrepayID, ""
+}
+
+// writeReturnFail 返回通信失败
+func writeReturnFail(w http.ResponseWriter, msg string) {
+	writeXML(w, map[string]string{
+		"return_code": "FAIL",
+		"return_msg":  msg,
+	})
+}
+
+// writeXML 以 xml 格式返回给微信
+func writeXML(w http.ResponseWriter, params map[string]string) {
+	data, err := utils.Map2XML(params)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	w.Write(data)
+}
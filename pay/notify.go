@@ -0,0 +1,88 @@
+package pay
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/aimuz/wechat-sdk/common"
+	"github.com/aimuz/wechat-sdk/utils"
+)
+
+type (
+	// NotifyFunc 处理支付结果通知，返回 error 时会通知微信处理失败，微信会重新发送通知
+	//
+	// 同样的通知可能会多次发送，需要正确处理重复的通知
+	NotifyFunc func(notify *WxPayNotifyReq) error
+
+	// NotifyHandler 支付结果通知处理，验证签名、appid 与 mch_id 后调用 NotifyFunc 并返回处理结果
+	NotifyHandler struct {
+		pay    *WePay
+		handle NotifyFunc
+	}
+)
+
+// NewNotifyHandler 创建支付结果通知处理，handle 用于处理通过验证的通知
+func (m *WePay) NewNotifyHandler(handle NotifyFunc) *NotifyHandler {
+	return &NotifyHandler{pay: m, handle: handle}
+}
+
+// ServeHTTP 实现 http.Handler
+func (h *NotifyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxNotifyBodySize))
+	if err != nil {
+		writeReturnFail(w, err.Error())
+		return
+	}
+
+	notify, err := h.parse(body)
+	if err != nil {
+		writeReturnFail(w, err.Error())
+		return
+	}
+
+	err = h.handle(notify)
+	if err != nil {
+		writeReturnFail(w, err.Error())
+		return
+	}
+
+	writeXML(w, map[string]string{
+		"return_code": "SUCCESS",
+		"return_msg":  "OK",
+	})
+}
+
+// parse 验证签名并解析通知内容，签名类型以通知中的 sign_type 为准
+func (h *NotifyHandler) parse(body []byte) (*WxPayNotifyReq, error) {
+	params, err := utils.XML2Map(body)
+	if err != nil {
+		return nil, err
+	}
+
+	payKey, err := h.pay.payKey()
+	if err != nil {
+		return nil, err
+	}
+
+	if !verifyRespSign(params, payKey, params["sign_type"]) {
+		return nil, ErrSignMismatch
+	}
+
+	if params["appid"] != h.pay.AppID {
+		return nil, errors.New(common.ErrAppIDMismatch)
+	}
+
+	if params["mch_id"] != h.pay.MchID {
+		return nil, errors.New(common.ErrMchIDMismatch)
+	}
+
+	notify := new(WxPayNotifyReq)
+	err = xml.Unmarshal(body, notify)
+	if err != nil {
+		return nil, err
+	}
+	return notify, nil
+}
@@ -0,0 +1,94 @@
+package pay
+
+import (
+	"time"
+
+	"github.com/aimuz/wechat-sdk/utils"
+)
+
+// LimitPayNoCredit 限制用户不能使用信用卡支付
+const LimitPayNoCredit = "no_credit"
+
+// 微信支付的时间均为北京时间
+var beijing = time.FixedZone("CST", 8*3600)
+
+// OrderOption 统一下单可选参数
+type OrderOption func(order *UnifiedOrder)
+
+// WithClientIP 支付提交客户端IP，未设置时使用本机IP，可以通过 utils.ClientIP 从请求中获取，
+// H5 支付的客户端IP 为 H5Pay 的必填参数
+func WithClientIP(ip string) OrderOption {
+	return func(order *UnifiedOrder) {
+		order.SpBillCreateIP = ip
+	}
+}
+
+// WithAttach 附加数据，在查询API和支付通知中原样返回
+func WithAttach(attach string) OrderOption {
+	return func(order *UnifiedOrder) {
+		order.Attach = attach
+	}
+}
+
+// WithDetail 商品详情，单品优惠活动时使用，可以通过 OrderDetail.Check 校验单品总金额
+func WithDetail(detail *OrderDetail) OrderOption {
+	return func(order *UnifiedOrder) {
+		order.Detail = detail
+	}
+}
+
+// WithTimeStart 订单生成时间
+func WithTimeStart(t time.Time) OrderOption {
+	return func(order *UnifiedOrder) {
+		order.TimeStart = t.In(beijing).Format("20060102150405")
+	}
+}
+
+// WithTimeExpire 订单失效时间，距离订单生成时间至少1分钟
+func WithTimeExpire(t time.Time) OrderOption {
+	return func(order *UnifiedOrder) {
+		order.TimeExpire = t.In(beijing).Format("20060102150405")
+	}
+}
+
+// WithGoodsTag 订单优惠标记，使用代金券或立减优惠功能时需要的参数
+func WithGoodsTag(goodsTag string) OrderOption {
+	return func(order *UnifiedOrder) {
+		order.GoodsTag = goodsTag
+	}
+}
+
+// WithLimitPay 限制支付方式，如 LimitPayNoCredit 不能使用信用卡支付
+func WithLimitPay(limitPay string) OrderOption {
+	return func(order *UnifiedOrder) {
+		order.LimitPay = limitPay
+	}
+}
+
+// applyOrderOptions 依次应用可选参数，未设置客户端IP时使用本机IP
+func applyOrderOptions(order *UnifiedOrder, opts []OrderOption) {
+	for _, opt := range opts {
+		opt(order)
+	}
+
+	if order.SpBillCreateIP == "" {
+		order.SpBillCreateIP = utils.LocalIP()
+	}
+}
+
+// RedPackOption 发送红包可选参数
+type RedPackOption func(req *SendRedPackReq)
+
+// WithRedPackClientIP 调用接口的机器IP，未设置时使用本机IP
+func WithRedPackClientIP(ip string) RedPackOption {
+	return func(req *SendRedPackReq) {
+		req.ClientIP = ip
+	}
+}
+
+// WithRedPackSceneID 发放红包使用场景，红包金额大于200或者小于1元时必传
+func WithRedPackSceneID(sceneID string) RedPackOption {
+	return func(req *SendRedPackReq) {
+		req.SceneID = sceneID
+	}
+}
@@ -1,13 +1,17 @@
 package pay
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/aimuz/wechat-sdk/common"
 	"github.com/aimuz/wechat-sdk/utils"
-	"github.com/jinzhu/copier"
 )
 
+// TradeTypeAPP APP支付交易类型
+const TradeTypeAPP = "APP"
+
 type (
 	// WePay 微信支付配置类
 	WePay struct {
@@ -16,10 +20,26 @@ type (
 		PayKey     string // 支付密钥
 		NotifyURL  string // 回调地址
 		TradeType  string // 小程序写"JSAPI",客户端写"APP"
+		SignType   string // 签名类型，"MD5" 或 "HMAC-SHA256"，默认MD5
 		Body       string // 商品描述 必填
 		CertFile   string // 微信支付平台证书
-		keyFile    string // 微信支付平台证书秘钥
+		KeyFile    string // 微信支付平台证书秘钥
 		RootCaFile string // 微信支付平台根证书
+
+		// Sandbox 仿真测试模式，支付接口使用 /sandboxnew/ 路径，并使用自动获取的仿真测试验签密钥签名，
+		// 仿真测试系统仅支持 MD5 签名
+		Sandbox bool
+
+		BaseURL          string        // 接口地址，默认为 common.PayBaseURL，可以指向本地的测试服务
+		BackupURL        string        // 容灾接口地址，未配置 BaseURL 时默认为 common.PayBackupBaseURL
+		FailoverCoolDown time.Duration // 切换到容灾地址后，经过多久切换回主地址，默认5分钟
+		Timeout          time.Duration // 请求接口的超时时间，默认10秒；下载账单时只限制建立连接与等待响应的时间
+
+		RSAPublicKeyFile string // 企业付款到银行卡使用的RSA公钥文件，为空时通过接口获取
+
+		sandbox  sandboxKey    // 缓存的仿真测试验签密钥
+		failover failover      // 容灾切换状态
+		bankKey  bankPublicKey // 缓存的企业付款到银行卡RSA公钥
 	}
 
 	// AppRet 返回的基本内容
@@ -51,40 +71,32 @@ type (
 
 		AppID    string `json:"appId,omitempty"`    // 应用ID
 		Package  string `json:"package,omitempty"`  // 扩展字段 统一下单接口返回的 prepay_id 参数值，提交格式如：prepay_id=*
-		SignType string `json:"signType,omitempty"` // 签名算法，暂支持 MD5
+		SignType string `json:"signType,omitempty"` // 签名算法，与统一下单的签名类型一致
 		PaySign  string `json:"paySign,omitempty"`  // 签名
 	}
 )
 
-// AppPay App支付
-func (m *WePay) AppPay(totalFee int) (results *AppPayRet, outTradeNo string, err error) {
-
-	outTradeNo = utils.GetTradeNO(m.MchID)
-	appUnifiedOrder := &AppUnifiedOrder{
+// AppPay App支付，可以通过 opts 设置客户端IP、附加数据等参数
+func (m *WePay) AppPay(totalFee int, opts ...OrderOption) (results *AppPayRet, outTradeNo string, err error) {
+	order := AppUnifiedOrder{
 		UnifiedOrder: UnifiedOrder{
-			AppID:          m.AppID,
-			MchID:          m.MchID,
-			NotifyURL:      m.NotifyURL,
-			TradeType:      m.TradeType,
-			SpBillCreateIP: "123.123.123.123", // Ip
-			OutTradeNo:     outTradeNo,
-			TotalFee:       totalFee,
-			Body:           m.Body,
-			NonceStr:       utils.RandomString(32),
+			TotalFee: totalFee,
 		},
 	}
-	t, err := utils.Struct2Map(appUnifiedOrder)
-	if err != nil {
-		return results, outTradeNo, err
-	}
+	applyOrderOptions(&order.UnifiedOrder, opts)
+	return m.AppPayStruct(order)
+}
 
-	// 获取签名
-	appUnifiedOrder.Sign, err = utils.GenWeChatPaySign(t, m.PayKey)
-	if err != nil {
-		return results, outTradeNo, err
+// AppPayStruct 自定义参数实现，order 中为空的公共参数使用 WePay 的配置填充，
+// 未设置 nonce_str、out_trade_no 时自动生成，trade_type 默认为 APP
+func (m *WePay) AppPayStruct(order AppUnifiedOrder) (results *AppPayRet, outTradeNo string, err error) {
+	m.fillUnifiedOrder(&order.UnifiedOrder)
+	if order.TradeType == "" {
+		order.TradeType = TradeTypeAPP
 	}
+	outTradeNo = order.OutTradeNo
 
-	unifiedOrderResp, err := NewUnifiedOrder(appUnifiedOrder)
+	unifiedOrderResp, err := m.unifiedOrder(&order)
 	if err != nil {
 		return results, outTradeNo, err
 	}
@@ -104,7 +116,7 @@ func (m *WePay) AppPay(totalFee int) (results *AppPayRet, outTradeNo string, err
 		return results, outTradeNo, err
 	}
 
-	results.Sign, err = utils.GenWeChatPaySign(r, m.PayKey)
+	results.Sign, err = m.sign(r)
 	if err != nil {
 		return results, outTradeNo, err
 	}
@@ -112,43 +124,32 @@ func (m *WePay) AppPay(totalFee int) (results *AppPayRet, outTradeNo string, err
 	return
 }
 
-// AppPayStruct 自定义参数实现，需要自定义
-func (m *WePay) AppPayStruct(order AppUnifiedOrder) (results *AppPayRet, outTradeNo string, err error) {
-	unifiedOrder := new(AppUnifiedOrder)
-	copier.Copy(order, &unifiedOrder)
-	return
-}
-
-// WaxPay 小程序支付
-func (m *WePay) WaxPay(totalFee int, openID string) (results *WaxPayRet, outTradeNo string, err error) {
-
-	outTradeNo = utils.GetTradeNO(m.MchID)
-	wxaUnifiedOrder := &WxaUnifiedOrder{
+// WaxPay 小程序支付，可以通过 opts 设置客户端IP、附加数据等参数
+func (m *WePay) WaxPay(totalFee int, openID string, opts ...OrderOption) (results *WaxPayRet, outTradeNo string, err error) {
+	order := WxaUnifiedOrder{
 		UnifiedOrder: UnifiedOrder{
-			AppID:          m.AppID,
-			MchID:          m.MchID,
-			NotifyURL:      m.NotifyURL,
-			TradeType:      m.TradeType,
-			SpBillCreateIP: "123.123.123.123", // Ip
-			OutTradeNo:     outTradeNo,
-			TotalFee:       totalFee,
-			Body:           m.Body,
-			NonceStr:       utils.RandomString(32),
+			TotalFee: totalFee,
 		},
 		OpenID: openID,
 	}
-	t, err := utils.Struct2Map(wxaUnifiedOrder)
-	if err != nil {
-		return results, outTradeNo, err
+	applyOrderOptions(&order.UnifiedOrder, opts)
+	return m.WaxPayStruct(order)
+}
+
+// WaxPayStruct 小程序支付自定义参数实现，order 中为空的公共参数使用 WePay 的配置填充，
+// 未设置 nonce_str、out_trade_no 时自动生成，trade_type 默认为 JSAPI
+func (m *WePay) WaxPayStruct(order WxaUnifiedOrder) (results *WaxPayRet, outTradeNo string, err error) {
+	if order.OpenID == "" {
+		return nil, "", errors.New(common.ErrOpenIDEmpty)
 	}
 
-	// 获取签名
-	wxaUnifiedOrder.Sign, err = utils.GenWeChatPaySign(t, m.PayKey)
-	if err != nil {
-		return results, outTradeNo, err
+	m.fillUnifiedOrder(&order.UnifiedOrder)
+	if order.TradeType == "" {
+		order.TradeType = TradeTypeJSAPI
 	}
+	outTradeNo = order.OutTradeNo
 
-	unifiedOrderResp, err := NewUnifiedOrder(wxaUnifiedOrder)
+	unifiedOrderResp, err := m.unifiedOrder(&order)
 	if err != nil {
 		return results, outTradeNo, err
 	}
@@ -157,9 +158,9 @@ func (m *WePay) WaxPay(totalFee int, openID string) (results *WaxPayRet, outTrad
 			Timestamp: fmt.Sprintf("%d", time.Now().Unix()),
 			NonceStr:  unifiedOrderResp.NonceStr,
 		},
-		AppID:    m.AppID,
+		AppID:    order.AppID,
 		Package:  "prepay_id=" + unifiedOrderResp.PrepayID,
-		SignType: "MD5",
+		SignType: m.signType(),
 	}
 
 	r, err := utils.Struct2Map(results)
@@ -167,21 +168,10 @@ func (m *WePay) WaxPay(totalFee int, openID string) (results *WaxPayRet, outTrad
 		return results, outTradeNo, err
 	}
 
-	results.PaySign, err = utils.GenWeChatPaySign(r, m.PayKey)
+	results.PaySign, err = m.sign(r)
 	if err != nil {
 		return results, outTradeNo, err
 	}
 
 	return
 }
-
-//// 公众号支付
-//func (m *WePay) H5Pay(totalFee int, openId string) (results *WaxPayRet, outTradeNo string, err error) {
-//
-//	return m.WaxPay(totalFee, openId)
-//}
-//
-//// 网页支付
-//func (m *WePay) WebPay(totalFee int, openId string) (results *WaxPayRet, outTradeNo string, err error) {
-//	return m.WaxPay(totalFee, openId)
-//}
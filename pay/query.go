@@ -0,0 +1,126 @@
+package pay
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/aimuz/wechat-sdk/common"
+	"github.com/aimuz/wechat-sdk/utils"
+)
+
+// 交易状态
+const (
+	TradeStateSuccess    = "SUCCESS"    // 支付成功
+	TradeStateRefund     = "REFUND"     // 转入退款
+	TradeStateNotPay     = "NOTPAY"     // 未支付
+	TradeStateClosed     = "CLOSED"     // 已关闭
+	TradeStateRevoked    = "REVOKED"    // 已撤销（付款码支付）
+	TradeStateUserPaying = "USERPAYING" // 用户支付中（付款码支付）
+	TradeStatePayError   = "PAYERROR"   // 支付失败(其他原因，如银行返回失败)
+)
+
+type (
+	// OrderQueryReq 查询订单请求参数，transaction_id 与 out_trade_no 二选一
+	OrderQueryReq struct {
+		AppID         string `json:"appid"`                    // 应用ID
+		MchID         string `json:"mch_id"`                   // 商户号
+		TransactionID string `json:"transaction_id,omitempty"` // 微信订单号，优先使用
+		OutTradeNo    string `json:"out_trade_no,omitempty"`   // 商户订单号
+		NonceStr      string `json:"nonce_str"`                // 随机字符串
+	}
+
+	// OrderQueryResp 查询订单返回
+	OrderQueryResp struct {
+		ReturnCode         string   `xml:"return_code"`
+		ReturnMsg          string   `xml:"return_msg"`
+		AppID              string   `xml:"appid"`
+		MchID              string   `xml:"mch_id"`
+		NonceStr           string   `xml:"nonce_str"`
+		Sign               string   `xml:"sign"`
+		ResultCode         string   `xml:"result_code"`
+		ErrCode            string   `xml:"err_code"`
+		ErrCodeDes         string   `xml:"err_code_des"`
+		DeviceInfo         string   `xml:"device_info"`          // 设备号
+		OpenID             string   `xml:"openid"`               // 用户标识
+		IsSubscribe        string   `xml:"is_subscribe"`         // 是否关注公众账号
+		TradeType          string   `xml:"trade_type"`           // 交易类型
+		TradeState         string   `xml:"trade_state"`          // 交易状态
+		BankType           string   `xml:"bank_type"`            // 付款银行
+		TotalFee           int      `xml:"total_fee"`            // 订单金额
+		SettlementTotalFee int      `xml:"settlement_total_fee"` // 应结订单金额
+		FeeType            string   `xml:"fee_type"`             // 货币种类
+		CashFee            int      `xml:"cash_fee"`             // 现金支付金额
+		CashFeeType        string   `xml:"cash_fee_type"`        // 现金支付货币类型
+		CouponFee          int      `xml:"coupon_fee"`           // 代金券金额
+		CouponCount        int      `xml:"coupon_count"`         // 代金券使用数量
+		Coupons            []Coupon `xml:"-"`                    // 代金券明细
+		TransactionID      string   `xml:"transaction_id"`       // 微信支付订单号
+		OutTradeNo         string   `xml:"out_trade_no"`         // 商户订单号
+		Attach             string   `xml:"attach"`               // 附加数据
+		TimeEnd            string   `xml:"time_end"`             // 支付完成时间，格式为yyyyMMddHHmmss
+		TradeStateDesc     string   `xml:"trade_state_desc"`     // 交易状态描述
+	}
+
+	// Coupon 代金券明细
+	Coupon struct {
+		CouponID   string // 代金券ID
+		CouponType string // 代金券类型，CASH 充值代金券，NO_CASH 非充值优惠券
+		CouponFee  int    // 单个代金券支付金额
+	}
+)
+
+// OrderQuery 查询订单，transactionID 与 outTradeNo 二选一
+func (m *WePay) OrderQuery(transactionID, outTradeNo string) (*OrderQueryResp, error) {
+	if transactionID == "" && outTradeNo == "" {
+		return nil, errors.New(common.ErrTradeNoEmpty)
+	}
+
+	req := &OrderQueryReq{
+		AppID:         m.AppID,
+		MchID:         m.MchID,
+		TransactionID: transactionID,
+		OutTradeNo:    outTradeNo,
+		NonceStr:      utils.RandomString(32),
+	}
+
+	resp := new(OrderQueryResp)
+	params, err := m.request(common.OrderQueryURL, req, resp)
+	if err != nil {
+		return resp, err
+	}
+
+	resp.Coupons = parseCoupons(params, resp.CouponCount)
+	return resp, nil
+}
+
+// maxIndexedCount 下标字段的最大数量，避免异常的 coupon_count 等字段导致大量分配内存
+const maxIndexedCount = 100
+
+// indexedCount 将下标字段的数量限制在 0 到 maxIndexedCount 之间
+func indexedCount(count int) int {
+	if count < 0 {
+		return 0
+	}
+	if count > maxIndexedCount {
+		return maxIndexedCount
+	}
+	return count
+}
+
+// parseCoupons 解析 coupon_id_$n 等下标字段，遇到不存在的 coupon_id_$n 时停止
+func parseCoupons(params map[string]string, count int) []Coupon {
+	coupons := make([]Coupon, 0)
+	for i := 0; i < indexedCount(count); i++ {
+		n := strconv.Itoa(i)
+		if _, ok := params["coupon_id_"+n]; !ok {
+			break
+		}
+		fee, _ := strconv.Atoi(params["coupon_fee_"+n])
+		coupons = append(coupons, Coupon{
+			CouponID:   params["coupon_id_"+n],
+			CouponType: params["coupon_type_"+n],
+			CouponFee:  fee,
+		})
+	}
+	return coupons
+}
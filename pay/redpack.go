@@ -18,9 +18,12 @@ type (
 		Send(payKey, certFile, keyFile, rootCaFile string) (*RedPackResp, error)
 	}
 
+	// redPackSendFunc 向指定的接口地址发送红包
+	redPackSendFunc func(url, payKey string, request *utils.Request) (*RedPackResp, error)
+
 	// SendRedPackReq 发送普通红包请求参数
 	SendRedPackReq struct {
-		XMLName      xml.Name `xml:"xml"`
+		XMLName      xml.Name `xml:"xml" json:"-"`
 		NonceStr     string   `xml:"nonce_str,omitempty" json:"nonce_str"`           // NonceStr 随机字符串
 		Sign         string   `xml:"sign,omitempty" json:"sign"`                     // Sign 签名
 		MchBillNo    string   `xml:"mch_billno,omitempty" json:"mch_billno"`         // MchBillNo 商户订单号
@@ -58,8 +61,8 @@ type (
 	}
 )
 
-// SendRedPack 简单调用方法
-func (m *WePay) SendRedPack(totalAmount int64, openID, sendName, wishing, actName, remark string) (string, *RedPackResp, error) {
+// SendRedPack 简单调用方法，可以通过 opts 设置调用接口的机器IP等参数
+func (m *WePay) SendRedPack(totalAmount int64, openID, sendName, wishing, actName, remark string, opts ...RedPackOption) (string, *RedPackResp, error) {
 	mchBillNo := utils.GetBillNo(m.MchID, 28)
 	req := &SendRedPackReq{
 		NonceStr:    utils.RandomString(32),
@@ -71,10 +74,15 @@ func (m *WePay) SendRedPack(totalAmount int64, openID, sendName, wishing, actNam
 		TotalAmount: totalAmount,
 		TotalNum:    1,
 		Wishing:     wishing,
-		ClientIP:    "123.123.123.123",
 		ActName:     actName,
 		Remark:      remark,
 	}
+	for _, opt := range opts {
+		opt(req)
+	}
+	if req.ClientIP == "" {
+		req.ClientIP = utils.LocalIP()
+	}
 	return m.sendRedPack(req)
 }
 
@@ -88,21 +96,50 @@ func (m *WePay) SendRedPackByStruct(req *SendRedPackReq) (string, *RedPackResp,
 }
 
 func (m *WePay) sendRedPack(req *SendRedPackReq) (string, *RedPackResp, error) {
-	resp, err := req.Send(m.PayKey, m.CertFile, m.keyFile, m.RootCaFile)
+	resp, err := m.postRedPack(common.SendRedPackURL, req.send)
+	return req.MchBillNo, resp, err
+}
+
+// postRedPack 使用 WePay 的密钥与证书调用 send 发送红包，并检查返回的业务结果
+func (m *WePay) postRedPack(url string, send redPackSendFunc) (*RedPackResp, error) {
+	payKey, err := m.payKey()
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := m.newRequest(true, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp *RedPackResp
+	err = m.do(url, func(url string) (err error) {
+		resp, err = send(url, payKey, request)
+		return err
+	})
 	if err != nil {
-		return req.MchBillNo, resp, err
+		return resp, err
 	}
 
 	err = resp.CheckErr()
 	if err != nil {
-		return req.MchBillNo, resp, err
+		return resp, err
 	}
 
-	return req.MchBillNo, resp, nil
+	return resp, nil
 }
 
 // Send 发送普通红包
 func (m *SendRedPackReq) Send(payKey string, certFile, keyFile, rootCaFile string) (*RedPackResp, error) {
+	request, err := utils.NewCertRequest(certFile, keyFile, rootCaFile)
+	if err != nil {
+		return nil, err
+	}
+	return m.send(common.SendRedPackURL, payKey, request)
+}
+
+// send 向 url 发送普通红包
+func (m *SendRedPackReq) send(url, payKey string, request *utils.Request) (*RedPackResp, error) {
 
 	tMap, err := utils.Struct2Map(m)
 	if err != nil {
@@ -119,16 +156,26 @@ func (m *SendRedPackReq) Send(payKey string, certFile, keyFile, rootCaFile strin
 		return nil, err
 	}
 
-	request, err := utils.NewCertRequest(certFile, keyFile, rootCaFile)
+	return postRedPackXML(url, data, payKey, request)
+}
+
+// postRedPackXML 使用双向证书请求 request 提交已签名的红包请求 xml 并解析返回结果
+func postRedPackXML(url string, data []byte, payKey string, request *utils.Request) (*RedPackResp, error) {
+	body, err := request.NewRequest("POST", url, data)
 	if err != nil {
 		return nil, err
 	}
 
-	body, err := request.NewRequest("POST", common.SendRedPackURL, data)
+	params, err := utils.XML2Map(body)
 	if err != nil {
 		return nil, err
 	}
 
+	// 红包接口的返回结果通常不带签名，带有签名时进行校验
+	if params["sign"] != "" && !verifyRespSign(params, payKey, utils.SignTypeMD5) {
+		return nil, ErrSignMismatch
+	}
+
 	resp := new(RedPackResp)
 	err = xml.Unmarshal(body, resp)
 	if err != nil {
@@ -0,0 +1,130 @@
+package pay
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/aimuz/wechat-sdk/common"
+	"github.com/aimuz/wechat-sdk/utils"
+)
+
+// 退款资金来源
+const (
+	RefundAccountUnsettled = "REFUND_SOURCE_UNSETTLED_FUNDS" // 未结算资金退款（默认使用未结算资金退款）
+	RefundAccountRecharge  = "REFUND_SOURCE_RECHARGE_FUNDS"  // 可用余额退款
+)
+
+type (
+	// RefundReq 申请退款请求参数，transaction_id 与 out_trade_no 二选一
+	RefundReq struct {
+		AppID         string `json:"appid"`                     // 应用ID，为空时使用 WePay.AppID
+		MchID         string `json:"mch_id"`                    // 商户号，为空时使用 WePay.MchID
+		NonceStr      string `json:"nonce_str"`                 // 随机字符串，为空时自动生成
+		TransactionID string `json:"transaction_id,omitempty"`  // 微信订单号
+		OutTradeNo    string `json:"out_trade_no,omitempty"`    // 商户订单号
+		OutRefundNo   string `json:"out_refund_no"`             // 商户退款单号，为空时自动生成，同一退款单号多次请求只退一笔
+		TotalFee      int    `json:"total_fee"`                 // 订单金额
+		RefundFee     int    `json:"refund_fee"`                // 退款金额，必须大于0，全额退款使用 FullRefund
+		RefundFeeType string `json:"refund_fee_type,omitempty"` // 退款货币种类，默认人民币：CNY
+		RefundDesc    string `json:"refund_desc,omitempty"`     // 退款原因，会在下发给用户的退款消息中体现
+		RefundAccount string `json:"refund_account,omitempty"`  // 退款资金来源，仅针对老资金流商户使用
+		NotifyURL     string `json:"notify_url,omitempty"`      // 退款结果通知url，优先于商户平台配置的回调地址
+	}
+
+	// RefundResp 申请退款返回
+	RefundResp struct {
+		ReturnCode          string         `xml:"return_code"`
+		ReturnMsg           string         `xml:"return_msg"`
+		AppID               string         `xml:"appid"`
+		MchID               string         `xml:"mch_id"`
+		NonceStr            string         `xml:"nonce_str"`
+		Sign                string         `xml:"sign"`
+		ResultCode          string         `xml:"result_code"`
+		ErrCode             string         `xml:"err_code"`
+		ErrCodeDes          string         `xml:"err_code_des"`
+		TransactionID       string         `xml:"transaction_id"`        // 微信订单号
+		OutTradeNo          string         `xml:"out_trade_no"`          // 商户订单号
+		OutRefundNo         string         `xml:"out_refund_no"`         // 商户退款单号
+		RefundID            string         `xml:"refund_id"`             // 微信退款单号
+		RefundFee           int            `xml:"refund_fee"`            // 退款总金额
+		SettlementRefundFee int            `xml:"settlement_refund_fee"` // 应结退款金额，去掉非充值代金券退款金额后的退款金额
+		TotalFee            int            `xml:"total_fee"`             // 订单金额
+		SettlementTotalFee  int            `xml:"settlement_total_fee"`  // 应结订单金额
+		FeeType             string         `xml:"fee_type"`              // 订单金额货币种类
+		CashFee             int            `xml:"cash_fee"`              // 现金支付金额
+		CashFeeType         string         `xml:"cash_fee_type"`         // 现金支付货币类型
+		CashRefundFee       int            `xml:"cash_refund_fee"`       // 现金退款金额
+		CouponRefundFee     int            `xml:"coupon_refund_fee"`     // 代金券退款总金额
+		CouponRefundCount   int            `xml:"coupon_refund_count"`   // 退款代金券使用数量
+		Coupons             []RefundCoupon `xml:"-"`                     // 退款代金券明细
+	}
+
+	// RefundCoupon 退款代金券明细
+	RefundCoupon struct {
+		CouponRefundID  string // 退款代金券ID
+		CouponType      string // 代金券类型，CASH 充值代金券，NO_CASH 非充值优惠券
+		CouponRefundFee int    // 单个退款代金券支付金额
+	}
+)
+
+// Refund 申请退款，支持全额退款与部分退款，一笔订单最多支持50次部分退款
+//
+// 需要配置 CertFile、KeyFile、RootCaFile
+func (m *WePay) Refund(req *RefundReq) (*RefundResp, error) {
+	if req.TransactionID == "" && req.OutTradeNo == "" {
+		return nil, errors.New(common.ErrTradeNoEmpty)
+	}
+
+	if req.RefundFee <= 0 || req.RefundFee > req.TotalFee {
+		return nil, errors.New(common.ErrRefundFeeInvalid)
+	}
+
+	if req.AppID == "" {
+		req.AppID = m.AppID
+	}
+	if req.MchID == "" {
+		req.MchID = m.MchID
+	}
+	if req.NonceStr == "" {
+		req.NonceStr = utils.RandomString(32)
+	}
+	if req.OutRefundNo == "" {
+		req.OutRefundNo = utils.GetTradeNO(m.MchID)
+	}
+
+	resp := new(RefundResp)
+	params, err := m.certRequest(common.RefundURL, req, resp)
+	if err != nil {
+		return resp, err
+	}
+
+	resp.Coupons = parseRefundCoupons(params, "", resp.CouponRefundCount)
+	return resp, nil
+}
+
+// FullRefund 按订单金额 TotalFee 全额退款，会覆盖 req.RefundFee
+//
+// 需要配置 CertFile、KeyFile、RootCaFile
+func (m *WePay) FullRefund(req *RefundReq) (*RefundResp, error) {
+	req.RefundFee = req.TotalFee
+	return m.Refund(req)
+}
+
+// parseRefundCoupons 解析 coupon_refund_id_$n 等下标字段，遇到不存在的 coupon_refund_id_$n 时停止，
+// 退款查询返回的字段多一级退款笔数下标，如 coupon_refund_id_$n_$m，此时 index 为 "_$n"
+func parseRefundCoupons(params map[string]string, index string, count int) []RefundCoupon {
+	coupons := make([]RefundCoupon, 0)
+	for i := 0; i < indexedCount(count); i++ {
+		n := index + "_" + strconv.Itoa(i)
+		if _, ok := params["coupon_refund_id"+n]; !ok {
+			break
+		}
+		fee, _ := strconv.Atoi(params["coupon_refund_fee"+n])
+		coupons = append(coupons, RefundCoupon{
+			CouponRefundID:  params["coupon_refund_id"+n],
+			CouponType:      params["coupon_type"+n],
+			CouponRefundFee: fee,
+		})
+	}
+	return coupons
+}
@@ -0,0 +1,110 @@
+package pay
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+
+	"github.com/aimuz/wechat-sdk/common"
+	"github.com/aimuz/wechat-sdk/utils"
+)
+
+type (
+	// RefundNotifyReq 退款结果通知，退款详情在加密的 req_info 中
+	RefundNotifyReq struct {
+		XMLName    xml.Name `xml:"xml"`
+		ReturnCode string   `xml:"return_code"`
+		ReturnMsg  string   `xml:"return_msg"`
+		AppID      string   `xml:"appid"`
+		MchID      string   `xml:"mch_id"`
+		NonceStr   string   `xml:"nonce_str"`
+		ReqInfo    string   `xml:"req_info"` // 加密信息
+	}
+
+	// RefundNotify 退款结果通知解密后的内容
+	RefundNotify struct {
+		XMLName             xml.Name `xml:"root"`
+		AppID               string   `xml:"-"`                     // 应用ID，来自通知外层
+		MchID               string   `xml:"-"`                     // 商户号，来自通知外层
+		TransactionID       string   `xml:"transaction_id"`        // 微信订单号
+		OutTradeNo          string   `xml:"out_trade_no"`          // 商户订单号
+		RefundID            string   `xml:"refund_id"`             // 微信退款单号
+		OutRefundNo         string   `xml:"out_refund_no"`         // 商户退款单号
+		TotalFee            int      `xml:"total_fee"`             // 订单金额
+		SettlementTotalFee  int      `xml:"settlement_total_fee"`  // 应结订单金额
+		RefundFee           int      `xml:"refund_fee"`            // 申请退款金额
+		SettlementRefundFee int      `xml:"settlement_refund_fee"` // 退款金额，去掉非充值代金券退款金额后的退款金额
+		RefundStatus        string   `xml:"refund_status"`         // 退款状态，SUCCESS 退款成功，CHANGE 退款异常，REFUNDCLOSE 退款关闭
+		SuccessTime         string   `xml:"success_time"`          // 退款成功时间，格式为 yyyy-MM-dd HH:mm:ss
+		RefundRecvAccout    string   `xml:"refund_recv_accout"`    // 退款入账账户
+		RefundAccount       string   `xml:"refund_account"`        // 退款资金来源
+		RefundRequestSource string   `xml:"refund_request_source"` // 退款发起来源，API 接口，VENDOR_PLATFORM 商户平台
+	}
+)
+
+// ParseRefundNotify 解析退款结果通知，并检查通知中的 appid 与 mch_id 与配置一致
+func (m *WePay) ParseRefundNotify(body []byte) (*RefundNotify, error) {
+	payKey, err := m.payKey()
+	if err != nil {
+		return nil, err
+	}
+
+	notify, err := ParseRefundNotify(body, payKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if notify.AppID != m.AppID {
+		return nil, errors.New(common.ErrAppIDMismatch)
+	}
+
+	if notify.MchID != m.MchID {
+		return nil, errors.New(common.ErrMchIDMismatch)
+	}
+	return notify, nil
+}
+
+// ParseRefundNotify 解析退款结果通知，body 为通知的原始内容，payKey 为 WePay.PayKey
+//
+// req_info 使用 AES-256-ECB 加密，密钥为 payKey 的 md5 值（32位小写）。
+// 不会检查 appid 与 mch_id，通常应使用 WePay.ParseRefundNotify
+func ParseRefundNotify(body []byte, payKey string) (*RefundNotify, error) {
+	req := new(RefundNotifyReq)
+	err := xml.Unmarshal(body, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ReturnCode != "SUCCESS" {
+		return nil, errors.New(req.ReturnMsg)
+	}
+
+	if req.ReqInfo == "" {
+		return nil, errors.New(common.ErrReqInfoEmpty)
+	}
+
+	crypted, err := base64.StdEncoding.DecodeString(req.ReqInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := md5.Sum([]byte(payKey))
+	key := []byte(hex.EncodeToString(sum[:]))
+
+	data, err := utils.AesECBDecrypt(crypted, key)
+	if err != nil {
+		return nil, err
+	}
+
+	notify := new(RefundNotify)
+	err = xml.Unmarshal(data, notify)
+	if err != nil {
+		return nil, err
+	}
+
+	notify.AppID = req.AppID
+	notify.MchID = req.MchID
+	return notify, nil
+}
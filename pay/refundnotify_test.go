@@ -0,0 +1,97 @@
+package pay
+
+import (
+	"crypto/aes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/aimuz/wechat-sdk/common"
+	"github.com/aimuz/wechat-sdk/utils"
+)
+
+// 微信支付文档中的退款结果通知解密后的 req_info 示例
+const refundNotifyReqInfo = `<root>
+<out_refund_no><![CDATA[131811191610442717309]]></out_refund_no>
+<out_trade_no><![CDATA[71106718111915575302817]]></out_trade_no>
+<refund_account><![CDATA[REFUND_SOURCE_RECHARGE_FUNDS]]></refund_account>
+<refund_fee><![CDATA[3960]]></refund_fee>
+<refund_id><![CDATA[50000408942018111907145868882]]></refund_id>
+<refund_recv_accout><![CDATA[支付用户零钱]]></refund_recv_accout>
+<refund_request_source><![CDATA[API]]></refund_request_source>
+<refund_status><![CDATA[SUCCESS]]></refund_status>
+<settlement_refund_fee><![CDATA[3960]]></settlement_refund_fee>
+<settlement_total_fee><![CDATA[3960]]></settlement_total_fee>
+<success_time><![CDATA[2018-11-19 16:24:13]]></success_time>
+<total_fee><![CDATA[3960]]></total_fee>
+<transaction_id><![CDATA[4200000215201811190261405420]]></transaction_id>
+</root>`
+
+// encryptReqInfo 按微信的方式加密 req_info
+func encryptReqInfo(t *testing.T, data, payKey string) string {
+	sum := md5.Sum([]byte(payKey))
+	block, err := aes.NewCipher([]byte(hex.EncodeToString(sum[:])))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	padded := utils.PKCS7Padding([]byte(data), block.BlockSize())
+	crypted := make([]byte, len(padded))
+	for i := 0; i < len(padded); i += block.BlockSize() {
+		block.Encrypt(crypted[i:i+block.BlockSize()], padded[i:i+block.BlockSize()])
+	}
+	return base64.StdEncoding.EncodeToString(crypted)
+}
+
+func TestWePayParseRefundNotify(t *testing.T) {
+	m := &WePay{AppID: "wx2421b1c4370ec43b", MchID: "10000100", PayKey: "192006250b4c09247ec02edce69f6a2d"}
+
+	tests := []struct {
+		name    string
+		appID   string
+		mchID   string
+		payKey  string
+		wantErr error
+	}{
+		{"ok", m.AppID, m.MchID, m.PayKey, nil},
+		{"appid mismatch", "wx0000000000000000", m.MchID, m.PayKey, errors.New(common.ErrAppIDMismatch)},
+		{"mch_id mismatch", m.AppID, "10000200", m.PayKey, errors.New(common.ErrMchIDMismatch)},
+		{"wrong key", m.AppID, m.MchID, "00000000000000000000000000000000", errors.New("")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := utils.Map2XML(map[string]string{
+				"return_code": "SUCCESS",
+				"appid":       tt.appID,
+				"mch_id":      tt.mchID,
+				"nonce_str":   "TeqClE3i0mvn3DrK",
+				"req_info":    encryptReqInfo(t, refundNotifyReqInfo, tt.payKey),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			notify, err := m.ParseRefundNotify(body)
+			if tt.wantErr != nil {
+				if err == nil {
+					t.Fatal("ParseRefundNotify() expected error")
+				}
+				if tt.wantErr.Error() != "" && err.Error() != tt.wantErr.Error() {
+					t.Fatalf("ParseRefundNotify() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if notify.OutRefundNo != "131811191610442717309" || notify.RefundFee != 3960 ||
+				notify.RefundStatus != RefundStatusSuccess || notify.SuccessTime != "2018-11-19 16:24:13" {
+				t.Errorf("ParseRefundNotify() = %+v", notify)
+			}
+		})
+	}
+}
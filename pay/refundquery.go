@@ -0,0 +1,122 @@
+package pay
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/aimuz/wechat-sdk/common"
+	"github.com/aimuz/wechat-sdk/utils"
+)
+
+// 退款状态
+const (
+	RefundStatusSuccess    = "SUCCESS"     // 退款成功
+	RefundStatusClose      = "REFUNDCLOSE" // 退款关闭
+	RefundStatusProcessing = "PROCESSING"  // 退款处理中
+	RefundStatusChange     = "CHANGE"      // 退款异常，需要通过商户平台手动处理
+)
+
+type (
+	// RefundQueryReq 查询退款请求参数，四个单号任选其一，优先级为 refund_id > out_refund_no > transaction_id > out_trade_no
+	RefundQueryReq struct {
+		AppID         string `json:"appid"`                    // 应用ID，为空时使用 WePay.AppID
+		MchID         string `json:"mch_id"`                   // 商户号，为空时使用 WePay.MchID
+		NonceStr      string `json:"nonce_str"`                // 随机字符串，为空时自动生成
+		TransactionID string `json:"transaction_id,omitempty"` // 微信订单号
+		OutTradeNo    string `json:"out_trade_no,omitempty"`   // 商户订单号
+		OutRefundNo   string `json:"out_refund_no,omitempty"`  // 商户退款单号
+		RefundID      string `json:"refund_id,omitempty"`      // 微信退款单号
+		Offset        int    `json:"offset,omitempty"`         // 偏移量，退款笔数超过10笔时分页查询
+	}
+
+	// RefundQueryResp 查询退款返回
+	RefundQueryResp struct {
+		ReturnCode         string       `xml:"return_code"`
+		ReturnMsg          string       `xml:"return_msg"`
+		AppID              string       `xml:"appid"`
+		MchID              string       `xml:"mch_id"`
+		NonceStr           string       `xml:"nonce_str"`
+		Sign               string       `xml:"sign"`
+		ResultCode         string       `xml:"result_code"`
+		ErrCode            string       `xml:"err_code"`
+		ErrCodeDes         string       `xml:"err_code_des"`
+		TotalRefundCount   int          `xml:"total_refund_count"`   // 订单总退款次数，使用 offset 分页时返回
+		TransactionID      string       `xml:"transaction_id"`       // 微信订单号
+		OutTradeNo         string       `xml:"out_trade_no"`         // 商户订单号
+		TotalFee           int          `xml:"total_fee"`            // 订单金额
+		SettlementTotalFee int          `xml:"settlement_total_fee"` // 应结订单金额
+		FeeType            string       `xml:"fee_type"`             // 订单金额货币种类
+		CashFee            int          `xml:"cash_fee"`             // 现金支付金额
+		RefundCount        int          `xml:"refund_count"`         // 本次返回的退款笔数
+		Refunds            []RefundItem `xml:"-"`                    // 退款明细
+	}
+
+	// RefundItem 单笔退款明细，对应 out_refund_no_$n 等下标字段
+	RefundItem struct {
+		OutRefundNo         string         // 商户退款单号
+		RefundID            string         // 微信退款单号
+		RefundChannel       string         // 退款渠道，ORIGINAL 原路退款，BALANCE 退回到余额
+		RefundFee           int            // 申请退款金额
+		SettlementRefundFee int            // 退款金额，去掉非充值代金券退款金额后的退款金额
+		CouponRefundFee     int            // 代金券退款总金额
+		CouponRefundCount   int            // 退款代金券使用数量
+		Coupons             []RefundCoupon // 退款代金券明细
+		RefundStatus        string         // 退款状态
+		RefundAccount       string         // 退款资金来源
+		RefundRecvAccout    string         // 退款入账账户
+		RefundSuccessTime   string         // 退款成功时间，格式为 yyyy-MM-dd HH:mm:ss
+	}
+)
+
+// RefundQuery 查询退款
+func (m *WePay) RefundQuery(req *RefundQueryReq) (*RefundQueryResp, error) {
+	if req.TransactionID == "" && req.OutTradeNo == "" && req.OutRefundNo == "" && req.RefundID == "" {
+		return nil, errors.New(common.ErrRefundNoEmpty)
+	}
+
+	if req.AppID == "" {
+		req.AppID = m.AppID
+	}
+	if req.MchID == "" {
+		req.MchID = m.MchID
+	}
+	if req.NonceStr == "" {
+		req.NonceStr = utils.RandomString(32)
+	}
+
+	resp := new(RefundQueryResp)
+	params, err := m.request(common.RefundQueryURL, req, resp)
+	if err != nil {
+		return resp, err
+	}
+
+	resp.Refunds = parseRefundItems(params, resp.RefundCount)
+	return resp, nil
+}
+
+// parseRefundItems 解析 out_refund_no_$n 等下标字段，遇到不存在的 out_refund_no_$n 时停止
+func parseRefundItems(params map[string]string, count int) []RefundItem {
+	items := make([]RefundItem, 0)
+	for i := 0; i < indexedCount(count); i++ {
+		n := "_" + strconv.Itoa(i)
+		if _, ok := params["out_refund_no"+n]; !ok {
+			break
+		}
+		item := RefundItem{
+			OutRefundNo:       params["out_refund_no"+n],
+			RefundID:          params["refund_id"+n],
+			RefundChannel:     params["refund_channel"+n],
+			RefundStatus:      params["refund_status"+n],
+			RefundAccount:     params["refund_account"+n],
+			RefundRecvAccout:  params["refund_recv_accout"+n],
+			RefundSuccessTime: params["refund_success_time"+n],
+		}
+		item.RefundFee, _ = strconv.Atoi(params["refund_fee"+n])
+		item.SettlementRefundFee, _ = strconv.Atoi(params["settlement_refund_fee"+n])
+		item.CouponRefundFee, _ = strconv.Atoi(params["coupon_refund_fee"+n])
+		item.CouponRefundCount, _ = strconv.Atoi(params["coupon_refund_count"+n])
+		item.Coupons = parseRefundCoupons(params, n, item.CouponRefundCount)
+		items = append(items, item)
+	}
+	return items
+}
@@ -0,0 +1,213 @@
+package pay
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+
+	"github.com/aimuz/wechat-sdk/utils"
+)
+
+// request 对请求参数签名后提交到 url，校验返回签名并解析到 resp，
+// 同时返回原始字段，用于解析 coupon_id_$n 这类下标字段
+func (m *WePay) request(url string, req, resp interface{}) (map[string]string, error) {
+	data, err := m.signXML(req)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := m.newRequest(false, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var body []byte
+	err = m.do(url, func(url string) error {
+		body, err = request.NewRequest("POST", url, data)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return m.parseResponse(body, resp, m.verifySign)
+}
+
+// certRequest 与 request 相同，使用双向证书发送请求，用于退款等接口
+func (m *WePay) certRequest(url string, req, resp interface{}) (map[string]string, error) {
+	data, err := m.signXML(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := m.certPost(url, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.parseResponse(body, resp, m.verifySign)
+}
+
+// mmpayRequest 使用双向证书发送请求，用于企业付款等营销接口，
+// 这些接口仅支持 MD5 签名，返回结果通常不带签名，带有签名时才进行校验
+func (m *WePay) mmpayRequest(url string, req, resp interface{}) (map[string]string, error) {
+	payKey, err := m.payKey()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := genSignXML(req, payKey, utils.SignTypeMD5)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := m.certPost(url, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.parseResponse(body, resp, m.verifyMD5Sign)
+}
+
+// certPost 使用双向证书提交请求 xml
+func (m *WePay) certPost(url string, data []byte) ([]byte, error) {
+	request, err := m.newRequest(true, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var body []byte
+	err = m.do(url, func(url string) error {
+		body, err = request.NewRequest("POST", url, data)
+		return err
+	})
+	return body, err
+}
+
+// signXML 使用 WePay 配置的签名类型生成签名并转换为请求 xml
+func (m *WePay) signXML(req interface{}) ([]byte, error) {
+	payKey, err := m.payKey()
+	if err != nil {
+		return nil, err
+	}
+	return genSignXML(req, payKey, m.signType())
+}
+
+// genSignXML 生成签名并转换为请求 xml，签名类型不是 MD5 时会带上 sign_type 参数
+func genSignXML(req interface{}, payKey, signType string) ([]byte, error) {
+	params, err := utils.Struct2Map(req)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := utils.NewSigner(signType)
+	if err != nil {
+		return nil, err
+	}
+
+	if signType != utils.SignTypeMD5 {
+		params["sign_type"] = signType
+	}
+
+	params["sign"], err = signer(params, payKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.Map2XML(params)
+}
+
+// parseResponse 校验返回结果并解析，verify 用于校验返回字段的签名
+func (m *WePay) parseResponse(body []byte, resp interface{}, verify func(params map[string]string) bool) (map[string]string, error) {
+	params, err := utils.XML2Map(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if params["return_code"] != "SUCCESS" {
+		return params, returnError(params, body)
+	}
+
+	if !verify(params) {
+		return params, ErrSignMismatch
+	}
+
+	err = xml.Unmarshal(body, resp)
+	if err != nil {
+		return params, err
+	}
+
+	if params["result_code"] != "SUCCESS" {
+		return params, &Error{Code: params["err_code"], Des: params["err_code_des"]}
+	}
+
+	return params, nil
+}
+
+// maxErrorBodySize 返回内容不是微信的 xml 时，错误信息中最多包含的返回内容长度
+const maxErrorBodySize = 128
+
+// returnError 通信失败的错误，return_msg 为空时（如 BaseURL 配置错误返回了 404 页面）使用返回内容的开头
+func returnError(params map[string]string, body []byte) error {
+	if params["return_msg"] != "" {
+		return errors.New(params["return_msg"])
+	}
+
+	if len(body) > maxErrorBodySize {
+		body = body[:maxErrorBodySize]
+	}
+	return fmt.Errorf("unexpected response (return_code=%q): %q", params["return_code"], body)
+}
+
+// verifySign 校验返回字段的签名
+func (m *WePay) verifySign(params map[string]string) bool {
+	payKey, err := m.payKey()
+	if err != nil {
+		return false
+	}
+	return verifyRespSign(params, payKey, m.signType())
+}
+
+// verifyMD5Sign 营销接口的返回结果通常不带签名，带有签名时按 MD5 校验
+func (m *WePay) verifyMD5Sign(params map[string]string) bool {
+	if params["sign"] == "" {
+		return true
+	}
+
+	payKey, err := m.payKey()
+	if err != nil {
+		return false
+	}
+	return verifyRespSign(params, payKey, utils.SignTypeMD5)
+}
+
+// verifyRespSign 校验返回字段的签名，参与签名的是返回的全部字段，不会修改 params
+func verifyRespSign(params map[string]string, payKey, signType string) bool {
+	verifyParams := make(map[string]string, len(params))
+	for k, v := range params {
+		verifyParams[k] = v
+	}
+	return VerifySign(verifyParams, payKey, params["sign"], signType)
+}
+
+// signType 配置的签名类型，默认为 MD5，仿真测试模式下固定为 MD5
+func (m *WePay) signType() string {
+	if m.SignType == "" || m.Sandbox {
+		return utils.SignTypeMD5
+	}
+	return m.SignType
+}
+
+// sign 使用配置的签名类型生成签名
+func (m *WePay) sign(params map[string]string) (string, error) {
+	signer, err := utils.NewSigner(m.signType())
+	if err != nil {
+		return "", err
+	}
+
+	payKey, err := m.payKey()
+	if err != nil {
+		return "", err
+	}
+	return signer(params, payKey)
+}
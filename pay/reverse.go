@@ -0,0 +1,120 @@
+package pay
+
+import (
+	"errors"
+	"time"
+
+	"github.com/aimuz/wechat-sdk/common"
+	"github.com/aimuz/wechat-sdk/utils"
+)
+
+// 撤销订单结果
+const (
+	ReverseStatusSuccess = "SUCCESS" // 撤销成功
+	ReverseStatusFailed  = "FAILED"  // 撤销失败，无需继续撤销
+	ReverseStatusRecall  = "RECALL"  // 达到重试次数后仍需要继续撤销
+)
+
+const (
+	// reverseMaxAttempts 撤销订单的最大调用次数
+	reverseMaxAttempts = 5
+	// reverseBackoff 第一次重试前的等待时间，之后每次翻倍
+	reverseBackoff = time.Second
+)
+
+type (
+	// ReverseReq 撤销订单请求参数
+	ReverseReq struct {
+		AppID      string `json:"appid"`        // 应用ID
+		MchID      string `json:"mch_id"`       // 商户号
+		OutTradeNo string `json:"out_trade_no"` // 商户订单号
+		NonceStr   string `json:"nonce_str"`    // 随机字符串
+	}
+
+	// ReverseResp 撤销订单返回
+	ReverseResp struct {
+		ReturnCode string `xml:"return_code"`
+		ReturnMsg  string `xml:"return_msg"`
+		AppID      string `xml:"appid"`
+		MchID      string `xml:"mch_id"`
+		NonceStr   string `xml:"nonce_str"`
+		Sign       string `xml:"sign"`
+		ResultCode string `xml:"result_code"`
+		ErrCode    string `xml:"err_code"`
+		ErrCodeDes string `xml:"err_code_des"`
+		Recall     string `xml:"recall"` // 是否需要继续调用撤销，Y 需要，N 不需要
+	}
+
+	// ReverseResult 撤销订单最终结果
+	ReverseResult struct {
+		Status     string       // 撤销结果，ReverseStatusSuccess、ReverseStatusFailed 或 ReverseStatusRecall
+		OutTradeNo string       // 商户订单号
+		Attempts   int          // 调用撤销接口的次数
+		Resp       *ReverseResp // 最后一次调用的返回
+	}
+)
+
+// Reverse 撤销订单，支付交易返回失败或支付系统超时时调用，支付成功的订单会被退款，未支付的订单会被关闭
+//
+// 返回 recall=Y、系统错误或网络错误时按指数退避重试，最多调用5次，其他错误直接返回 ReverseStatusFailed。
+// 撤销未成功时同时返回最后一次的错误。
+// 需要配置 CertFile、KeyFile、RootCaFile
+func (m *WePay) Reverse(outTradeNo string) (*ReverseResult, error) {
+	if outTradeNo == "" {
+		return nil, errors.New(common.ErrOutTradeNoEmpty)
+	}
+
+	result := &ReverseResult{OutTradeNo: outTradeNo}
+	backoff := reverseBackoff
+	for {
+		result.Attempts++
+		resp, err := m.reverse(outTradeNo)
+		result.Resp = resp
+		if err == nil {
+			result.Status = ReverseStatusSuccess
+			return result, nil
+		}
+
+		if !needRecall(resp, err) {
+			result.Status = ReverseStatusFailed
+			return result, err
+		}
+
+		if result.Attempts >= reverseMaxAttempts {
+			result.Status = ReverseStatusRecall
+			return result, err
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// reverse 调用一次撤销订单接口
+func (m *WePay) reverse(outTradeNo string) (*ReverseResp, error) {
+	req := &ReverseReq{
+		AppID:      m.AppID,
+		MchID:      m.MchID,
+		OutTradeNo: outTradeNo,
+		NonceStr:   utils.RandomString(32),
+	}
+
+	resp := new(ReverseResp)
+	_, err := m.certRequest(common.ReverseURL, req, resp)
+	return resp, err
+}
+
+// needRecall 撤销失败后是否需要重新调用，仅在返回 recall=Y、系统错误或网络错误时重试，
+// 证书配置错误、签名错误等其他错误不会因为重试而成功
+func needRecall(resp *ReverseResp, err error) bool {
+	if resp != nil && resp.Recall == "Y" {
+		return true
+	}
+
+	if errors.Is(err, ErrSystemError) {
+		return true
+	}
+
+	// 连接失败或服务端返回 5xx，结果未知
+	return needFailover(err)
+}
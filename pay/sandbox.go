@@ -0,0 +1,92 @@
+package pay
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/aimuz/wechat-sdk/common"
+	"github.com/aimuz/wechat-sdk/utils"
+)
+
+// sandboxPathPrefix 仿真测试系统接口路径前缀
+const sandboxPathPrefix = "/sandboxnew"
+
+type (
+	// SandboxSignKeyReq 获取仿真测试验签密钥请求参数
+	SandboxSignKeyReq struct {
+		MchID    string `json:"mch_id"`    // 商户号
+		NonceStr string `json:"nonce_str"` // 随机字符串
+	}
+
+	// SandboxSignKeyResp 获取仿真测试验签密钥返回
+	SandboxSignKeyResp struct {
+		ReturnCode     string `xml:"return_code"`
+		ReturnMsg      string `xml:"return_msg"`
+		MchID          string `xml:"mch_id"`
+		SandboxSignKey string `xml:"sandbox_signkey"` // 仿真测试验签密钥
+	}
+
+	// sandboxKey 缓存的仿真测试验签密钥
+	sandboxKey struct {
+		mu  sync.Mutex
+		key string
+	}
+)
+
+// SandboxSignKey 获取仿真测试验签密钥，使用 PayKey 签名，首次获取后会缓存
+func (m *WePay) SandboxSignKey() (string, error) {
+	m.sandbox.mu.Lock()
+	defer m.sandbox.mu.Unlock()
+
+	if m.sandbox.key != "" {
+		return m.sandbox.key, nil
+	}
+
+	req := &SandboxSignKeyReq{
+		MchID:    m.MchID,
+		NonceStr: utils.RandomString(32),
+	}
+
+	data, err := genSignXML(req, m.PayKey, utils.SignTypeMD5)
+	if err != nil {
+		return "", err
+	}
+
+	request, err := m.newRequest(false, false)
+	if err != nil {
+		return "", err
+	}
+
+	var body []byte
+	err = m.do(common.SandboxSignKeyURL, func(url string) error {
+		body, err = request.NewRequest("POST", url, data)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	params, err := utils.XML2Map(body)
+	if err != nil {
+		return "", err
+	}
+
+	if params["return_code"] != "SUCCESS" {
+		return "", returnError(params, body)
+	}
+
+	if params["sandbox_signkey"] == "" {
+		return "", errors.New(common.ErrSandboxSignKeyEmpty)
+	}
+
+	m.sandbox.key = params["sandbox_signkey"]
+	return m.sandbox.key, nil
+}
+
+// payKey 签名使用的密钥，仿真测试模式下为仿真测试验签密钥
+func (m *WePay) payKey() (string, error) {
+	if !m.Sandbox {
+		return m.PayKey, nil
+	}
+	return m.SandboxSignKey()
+}
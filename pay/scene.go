@@ -0,0 +1,49 @@
+package pay
+
+import "encoding/json"
+
+// H5 支付场景类型
+const (
+	H5TypeWap     = "Wap"     // 网页
+	H5TypeIOS     = "IOS"     // iOS 应用
+	H5TypeAndroid = "Android" // Android 应用
+)
+
+type (
+	// SceneInfo 场景信息，提交时会序列化为 json 字符串
+	SceneInfo struct {
+		H5Info    *H5Info    `json:"h5_info,omitempty"`    // H5 支付场景信息
+		StoreInfo *StoreInfo `json:"store_info,omitempty"` // 门店信息
+	}
+
+	// StoreInfo 门店信息
+	StoreInfo struct {
+		ID       string `json:"id"`                  // 门店编号，由商户自定义
+		Name     string `json:"name,omitempty"`      // 门店名称，由商户自定义
+		AreaCode string `json:"area_code,omitempty"` // 门店所在地行政区划码
+		Address  string `json:"address,omitempty"`   // 门店详细地址，由商户自定义
+	}
+
+	// H5Info H5 支付场景信息，按 Type 填写对应的字段
+	H5Info struct {
+		Type        string `json:"type"`                   // 场景类型，Wap、IOS 或 Android
+		AppName     string `json:"app_name,omitempty"`     // 应用名，IOS、Android 必填
+		BundleID    string `json:"bundle_id,omitempty"`    // iOS 平台 bundle_id
+		PackageName string `json:"package_name,omitempty"` // Android 平台 package_name
+		WapURL      string `json:"wap_url,omitempty"`      // WAP 网站URL地址，Wap 必填
+		WapName     string `json:"wap_name,omitempty"`     // WAP 网站名，Wap 必填
+	}
+
+	// sceneInfo 用于避免 MarshalText 递归调用
+	sceneInfo SceneInfo
+)
+
+// MarshalText 序列化为 json 字符串，xml 与 json 编码时都会使用
+func (s SceneInfo) MarshalText() ([]byte, error) {
+	return json.Marshal(sceneInfo(s))
+}
+
+// UnmarshalText 从 json 字符串解析
+func (s *SceneInfo) UnmarshalText(data []byte) error {
+	return json.Unmarshal(data, (*sceneInfo)(s))
+}
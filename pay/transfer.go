@@ -0,0 +1,143 @@
+package pay
+
+import (
+	"errors"
+
+	"github.com/aimuz/wechat-sdk/common"
+	"github.com/aimuz/wechat-sdk/utils"
+)
+
+// 企业付款校验用户姓名选项
+const (
+	CheckNameNo    = "NO_CHECK"    // 不校验真实姓名
+	CheckNameForce = "FORCE_CHECK" // 强校验真实姓名，需要填写 ReUserName
+)
+
+// 企业付款状态
+const (
+	TransferStatusSuccess    = "SUCCESS"    // 转账成功
+	TransferStatusFailed     = "FAILED"     // 转账失败
+	TransferStatusProcessing = "PROCESSING" // 处理中
+)
+
+type (
+	// TransferReq 企业付款到零钱请求参数
+	TransferReq struct {
+		MchAppID       string `json:"mch_appid"`                  // 商户账号appid，为空时使用 WePay.AppID
+		MchID          string `json:"mchid"`                      // 商户号，为空时使用 WePay.MchID
+		DeviceInfo     string `json:"device_info,omitempty"`      // 设备号
+		NonceStr       string `json:"nonce_str"`                  // 随机字符串，为空时自动生成
+		PartnerTradeNo string `json:"partner_trade_no"`           // 商户订单号，为空时自动生成，重试时必须使用原商户订单号
+		OpenID         string `json:"openid"`                     // 用户在 MchAppID 下的 openid
+		CheckName      string `json:"check_name"`                 // 校验用户姓名选项，为空时为 CheckNameNo
+		ReUserName     string `json:"re_user_name,omitempty"`     // 收款用户姓名，CheckNameForce 时必填
+		Amount         int    `json:"amount"`                     // 付款金额，单位为分
+		Desc           string `json:"desc"`                       // 付款备注
+		SpBillCreateIP string `json:"spbill_create_ip,omitempty"` // 调用接口的机器IP，为空时使用本机IP
+	}
+
+	// TransferResp 企业付款到零钱返回
+	TransferResp struct {
+		ReturnCode     string `xml:"return_code"`
+		ReturnMsg      string `xml:"return_msg"`
+		MchAppID       string `xml:"mch_appid"`
+		MchID          string `xml:"mchid"`
+		DeviceInfo     string `xml:"device_info"`
+		NonceStr       string `xml:"nonce_str"`
+		ResultCode     string `xml:"result_code"`
+		ErrCode        string `xml:"err_code"`
+		ErrCodeDes     string `xml:"err_code_des"`
+		PartnerTradeNo string `xml:"partner_trade_no"` // 商户订单号
+		PaymentNo      string `xml:"payment_no"`       // 微信付款单号
+		PaymentTime    string `xml:"payment_time"`     // 付款成功时间
+	}
+
+	// TransferInfoReq 查询企业付款请求参数
+	TransferInfoReq struct {
+		AppID          string `json:"appid"`            // 应用ID
+		MchID          string `json:"mch_id"`           // 商户号
+		NonceStr       string `json:"nonce_str"`        // 随机字符串
+		PartnerTradeNo string `json:"partner_trade_no"` // 商户订单号
+	}
+
+	// TransferInfoResp 查询企业付款返回
+	TransferInfoResp struct {
+		ReturnCode     string `xml:"return_code"`
+		ReturnMsg      string `xml:"return_msg"`
+		ResultCode     string `xml:"result_code"`
+		ErrCode        string `xml:"err_code"`
+		ErrCodeDes     string `xml:"err_code_des"`
+		AppID          string `xml:"appid"`
+		MchID          string `xml:"mch_id"`
+		PartnerTradeNo string `xml:"partner_trade_no"` // 商户订单号
+		DetailID       string `xml:"detail_id"`        // 微信付款单号
+		Status         string `xml:"status"`           // 转账状态，TransferStatusSuccess、TransferStatusFailed 或 TransferStatusProcessing
+		Reason         string `xml:"reason"`           // 失败原因
+		OpenID         string `xml:"openid"`           // 收款用户openid
+		TransferName   string `xml:"transfer_name"`    // 收款用户姓名
+		PaymentAmount  int    `xml:"payment_amount"`   // 付款金额，单位为分
+		TransferTime   string `xml:"transfer_time"`    // 发起转账的时间
+		PaymentTime    string `xml:"payment_time"`     // 付款成功时间
+		Desc           string `xml:"desc"`             // 付款备注
+	}
+)
+
+// Transfer 企业付款到零钱，付款结果以返回为准，返回 ErrSystemError 等结果未明确的错误时，
+// 需要使用原商户订单号重试或通过 GetTransferInfo 查询，避免重复付款
+//
+// 需要配置 CertFile、KeyFile、RootCaFile
+func (m *WePay) Transfer(req *TransferReq) (*TransferResp, error) {
+	if req.OpenID == "" {
+		return nil, errors.New(common.ErrOpenIDEmpty)
+	}
+	if req.Amount <= 0 {
+		return nil, errors.New(common.ErrAmountInvalid)
+	}
+
+	if req.CheckName == "" {
+		req.CheckName = CheckNameNo
+	}
+	if req.CheckName == CheckNameForce && req.ReUserName == "" {
+		return nil, errors.New(common.ErrReUserNameEmpty)
+	}
+
+	if req.MchAppID == "" {
+		req.MchAppID = m.AppID
+	}
+	if req.MchID == "" {
+		req.MchID = m.MchID
+	}
+	if req.NonceStr == "" {
+		req.NonceStr = utils.RandomString(32)
+	}
+	if req.PartnerTradeNo == "" {
+		req.PartnerTradeNo = utils.GetTradeNO(m.MchID)
+	}
+	if req.SpBillCreateIP == "" {
+		req.SpBillCreateIP = utils.LocalIP()
+	}
+
+	resp := new(TransferResp)
+	_, err := m.mmpayRequest(common.TransfersURL, req, resp)
+	return resp, err
+}
+
+// GetTransferInfo 查询企业付款结果，仅支持查询30天内的订单
+//
+// 需要配置 CertFile、KeyFile、RootCaFile
+func (m *WePay) GetTransferInfo(partnerTradeNo string) (*TransferInfoResp, error) {
+	if partnerTradeNo == "" {
+		return nil, errors.New(common.ErrPartnerTradeNoEmpty)
+	}
+
+	req := &TransferInfoReq{
+		AppID:          m.AppID,
+		MchID:          m.MchID,
+		NonceStr:       utils.RandomString(32),
+		PartnerTradeNo: partnerTradeNo,
+	}
+
+	resp := new(TransferInfoResp)
+	_, err := m.mmpayRequest(common.GetTransferInfoURL, req, resp)
+	return resp, err
+}
@@ -5,15 +5,25 @@ import (
 	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/hmac"
 	"crypto/md5"
+	crand "crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"encoding/pem"
+	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"math/rand"
+	"net"
 	"net/http"
 	"sort"
 	"strconv"
@@ -23,7 +33,27 @@ import (
 	"github.com/aimuz/wechat-sdk/common"
 )
 
-// NewRequest 请求包装
+// StatusError 服务端返回 5xx 状态码
+type StatusError struct {
+	StatusCode int    // 状态码
+	Status     string // 状态
+}
+
+// Error 实现 error
+func (e *StatusError) Error() string {
+	return "unexpected response status: " + e.Status
+}
+
+// checkStatus 服务端返回 5xx 时关闭 Body 并返回 StatusError
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode < http.StatusInternalServerError {
+		return nil
+	}
+	resp.Body.Close()
+	return &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+}
+
+// NewRequest 请求包装，服务端返回 5xx 时返回 StatusError
 func NewRequest(method, url string, data []byte) (body []byte, err error) {
 
 	if method == "GET" {
@@ -42,6 +72,11 @@ func NewRequest(method, url string, data []byte) (body []byte, err error) {
 		return nil, err
 	}
 
+	err = checkStatus(resp)
+	if err != nil {
+		return nil, err
+	}
+
 	body, err = ioutil.ReadAll(resp.Body)
 	defer resp.Body.Close()
 	if err != nil {
@@ -51,6 +86,25 @@ func NewRequest(method, url string, data []byte) (body []byte, err error) {
 	return body, err
 }
 
+// NewStreamRequest 请求包装，返回未读取的 Body，用于下载账单等大文件，调用方负责关闭
+func NewStreamRequest(method, url string, data []byte) (io.ReadCloser, error) {
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(data))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkStatus(resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
 // Request 请求包装体
 type Request struct {
 	Client *http.Client
@@ -103,7 +157,7 @@ func NewCertRequest(certFile, keyFile, rootCaFile string) (*Request, error) {
 	return &Request{Client: client}, nil
 }
 
-// NewRequest 发送请求
+// NewRequest 发送请求，服务端返回 5xx 时返回 StatusError
 func (m *Request) NewRequest(method, url string, data []byte) (body []byte, err error) {
 	if method == "GET" {
 		url = fmt.Sprint(url, "?", string(data))
@@ -119,6 +173,11 @@ func (m *Request) NewRequest(method, url string, data []byte) (body []byte, err
 		return body, err
 	}
 
+	err = checkStatus(resp)
+	if err != nil {
+		return nil, err
+	}
+
 	body, err = ioutil.ReadAll(resp.Body)
 	defer resp.Body.Close()
 	if err != nil {
@@ -128,6 +187,25 @@ func (m *Request) NewRequest(method, url string, data []byte) (body []byte, err
 	return body, err
 }
 
+// NewStreamRequest 发送请求，返回未读取的 Body，调用方负责关闭
+func (m *Request) NewStreamRequest(method, url string, data []byte) (io.ReadCloser, error) {
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(data))
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := m.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkStatus(resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
 // Struct2Map struct to map，依赖 json tab
 func Struct2Map(r interface{}) (s map[string]string, err error) {
 	var temp map[string]interface{}
@@ -149,6 +227,65 @@ func Struct2Map(r interface{}) (s map[string]string, err error) {
 	return result, nil
 }
 
+// XML2Map 将微信返回的 xml 解析为 map，只解析根节点下的一级字段
+func XML2Map(data []byte) (map[string]string, error) {
+	result := make(map[string]string)
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	var (
+		key   string
+		depth int
+	)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 2 {
+				key = t.Name.Local
+				result[key] = ""
+			}
+		case xml.CharData:
+			if depth == 2 {
+				result[key] += string(t)
+			}
+		case xml.EndElement:
+			depth--
+		}
+	}
+	return result, nil
+}
+
+// Map2XML map 转换为微信请求所需的 xml，值为空的字段会被忽略
+func Map2XML(m map[string]string) ([]byte, error) {
+	keys := make([]string, 0, len(m))
+	for k, v := range m {
+		if v != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	buf := new(bytes.Buffer)
+	buf.WriteString("<xml>")
+	for _, k := range keys {
+		buf.WriteString("<" + k + ">")
+		if err := xml.EscapeText(buf, []byte(m[k])); err != nil {
+			return nil, err
+		}
+		buf.WriteString("</" + k + ">")
+	}
+	buf.WriteString("</xml>")
+	return buf.Bytes(), nil
+}
+
 // ToStringE interface to string
 func ToStringE(i interface{}) (string, error) {
 	switch s := i.(type) {
@@ -193,19 +330,29 @@ func ToStringE(i interface{}) (string, error) {
 	}
 }
 
-// GenWeChatPaySign 生成微信签名
-func GenWeChatPaySign(m map[string]string, payKey string) (string, error) {
-	delete(m, "sign")
-	var signData []string
-	for k, v := range m {
-		if v != "" {
-			signData = append(signData, fmt.Sprintf("%s=%s", k, v))
-		}
+// 签名类型
+const (
+	SignTypeMD5        = "MD5"
+	SignTypeHMACSHA256 = "HMAC-SHA256"
+)
+
+// Signer 微信支付签名方法
+type Signer func(m map[string]string, payKey string) (string, error)
+
+// NewSigner 根据签名类型获取签名方法，signType 为空时使用 MD5
+func NewSigner(signType string) (Signer, error) {
+	switch signType {
+	case "", SignTypeMD5:
+		return GenWeChatPaySign, nil
+	case SignTypeHMACSHA256:
+		return GenWeChatPaySignHMACSHA256, nil
 	}
+	return nil, fmt.Errorf("unsupported sign type: %s", signType)
+}
 
-	sort.Strings(signData)
-	signStr := strings.Join(signData, "&")
-	signStr = signStr + "&key=" + payKey
+// GenWeChatPaySign 生成微信签名
+func GenWeChatPaySign(m map[string]string, payKey string) (string, error) {
+	signStr := genSignStr(m, payKey)
 
 	c := md5.New()
 	_, err := c.Write([]byte(signStr))
@@ -221,6 +368,35 @@ func GenWeChatPaySign(m map[string]string, payKey string) (string, error) {
 	return sign, nil
 }
 
+// GenWeChatPaySignHMACSHA256 生成 HMAC-SHA256 微信签名
+func GenWeChatPaySignHMACSHA256(m map[string]string, payKey string) (string, error) {
+	signStr := genSignStr(m, payKey)
+
+	c := hmac.New(sha256.New, []byte(payKey))
+	_, err := c.Write([]byte(signStr))
+	if err != nil {
+		return "", err
+	}
+
+	sign := strings.ToUpper(hex.EncodeToString(c.Sum(nil)))
+	return sign, nil
+}
+
+// genSignStr 按参数名 ASCII 码从小到大排序拼接待签名字符串，值为空的参数不参与签名
+func genSignStr(m map[string]string, payKey string) string {
+	delete(m, "sign")
+	var signData []string
+	for k, v := range m {
+		if v != "" {
+			signData = append(signData, fmt.Sprintf("%s=%s", k, v))
+		}
+	}
+
+	sort.Strings(signData)
+	signStr := strings.Join(signData, "&")
+	return signStr + "&key=" + payKey
+}
+
 // GetTradeNO 生成订单号，不推荐直接使用
 func GetTradeNO(prefix string) string {
 	now := time.Now()
@@ -241,7 +417,6 @@ func GetBillNo(prefix string, length int) string {
 	if a := length - len(str); a > 0 {
 		str = str + RandomLenNum(a)
 	}
-	fmt.Println(str)
 	return str[:length]
 }
 
@@ -334,3 +509,144 @@ func AesDecrypt(crypted, key []byte) ([]byte, error) {
 	origData = PKCS7UnPadding(origData)
 	return origData, nil
 }
+
+// AesECBDecrypt Aes ECB 模式解密，用于退款结果通知中 req_info 的解密
+func AesECBDecrypt(crypted, key []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	blockSize := block.BlockSize()
+	if len(crypted) == 0 || len(crypted)%blockSize != 0 {
+		return nil, errors.New("crypto/cipher: input not full blocks")
+	}
+
+	origData := make([]byte, len(crypted))
+	for i := 0; i < len(crypted); i += blockSize {
+		block.Decrypt(origData[i:i+blockSize], crypted[i:i+blockSize])
+	}
+
+	unpadding := int(origData[len(origData)-1])
+	if unpadding == 0 || unpadding > blockSize {
+		return nil, errors.New("crypto/cipher: invalid padding")
+	}
+	for _, b := range origData[len(origData)-unpadding:] {
+		if int(b) != unpadding {
+			return nil, errors.New("crypto/cipher: invalid padding")
+		}
+	}
+	return origData[:len(origData)-unpadding], nil
+}
+
+// ParseRSAPublicKey 解析 PEM 格式的 RSA 公钥，支持 PKCS#1（BEGIN RSA PUBLIC KEY）与 PKIX（BEGIN PUBLIC KEY）
+func ParseRSAPublicKey(pemData []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, errors.New("failed to decode public key pem")
+	}
+
+	if block.Type == "RSA PUBLIC KEY" {
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	rsaPub, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("public key is not rsa")
+	}
+	return rsaPub, nil
+}
+
+// RSAEncryptOAEP RSA-OAEP（SHA1）加密后 base64 编码，用于企业付款到银行卡的收款方银行卡号与姓名
+func RSAEncryptOAEP(pub *rsa.PublicKey, data []byte) (string, error) {
+	encrypted, err := rsa.EncryptOAEP(sha1.New(), crand.Reader, pub, data, nil)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(encrypted), nil
+}
+
+// LocalIP 本机IP，取第一个非回环的 IPv4 地址，获取失败时返回 127.0.0.1
+func LocalIP() string {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return "127.0.0.1"
+	}
+
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if ok && !ipNet.IP.IsLoopback() && ipNet.IP.To4() != nil {
+			return ipNet.IP.String()
+		}
+	}
+	return "127.0.0.1"
+}
+
+// ClientIP 获取请求的客户端IP
+//
+// 只有直接连接的地址在 trustedProxies 中时才会使用 X-Forwarded-For，
+// 从右向左跳过可信代理，返回第一个不可信的地址，避免客户端伪造请求头。
+// trustedProxies 可以是IP，也可以是CIDR，如 "10.0.0.0/8"。请求头中不是IP的值会被忽略
+func ClientIP(r *http.Request, trustedProxies ...string) string {
+	remoteIP, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remoteIP = r.RemoteAddr
+	}
+
+	if !isTrustedProxy(remoteIP, trustedProxies) {
+		return remoteIP
+	}
+
+	forwarded := r.Header.Get("X-Forwarded-For")
+	if forwarded == "" {
+		if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(realIP) != nil {
+			return realIP
+		}
+		return remoteIP
+	}
+
+	// 跳过不是IP的地址，全部为可信代理时返回最左侧的地址
+	var leftmost string
+	ips := strings.Split(forwarded, ",")
+	for i := len(ips) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(ips[i])
+		if net.ParseIP(ip) == nil {
+			continue
+		}
+		if !isTrustedProxy(ip, trustedProxies) {
+			return ip
+		}
+		leftmost = ip
+	}
+
+	if leftmost != "" {
+		return leftmost
+	}
+	return remoteIP
+}
+
+// isTrustedProxy 判断 ip 是否在可信代理中
+func isTrustedProxy(ip string, trustedProxies []string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, proxy := range trustedProxies {
+		if strings.Contains(proxy, "/") {
+			_, ipNet, err := net.ParseCIDR(proxy)
+			if err == nil && ipNet.Contains(parsed) {
+				return true
+			}
+			continue
+		}
+		if parsed.Equal(net.ParseIP(proxy)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/aes"
+	"net/http/httptest"
+	"testing"
+)
+
+// aesECBEncrypt 测试用的 AES ECB 加密，data 需要已经填充
+func aesECBEncrypt(t *testing.T, data, key []byte) []byte {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	crypted := make([]byte, len(data))
+	for i := 0; i < len(data); i += block.BlockSize() {
+		block.Encrypt(crypted[i:i+block.BlockSize()], data[i:i+block.BlockSize()])
+	}
+	return crypted
+}
+
+func TestAesECBDecrypt(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	tests := []struct {
+		name    string
+		padded  []byte
+		want    []byte
+		wantErr bool
+	}{
+		{"short padding", append([]byte("<root>ok</root>"), 1), []byte("<root>ok</root>"), false},
+		{"full block padding", append([]byte("0123456789abcdef"), bytes.Repeat([]byte{16}, 16)...), []byte("0123456789abcdef"), false},
+		{"multi byte padding", append([]byte("0123456789abc"), 3, 3, 3), []byte("0123456789abc"), false},
+		{"zero padding", append([]byte("0123456789abcde"), 0), nil, true},
+		{"padding too large", append([]byte("0123456789abcde"), 17), nil, true},
+		{"inconsistent padding", append([]byte("0123456789abc"), 1, 2, 3), nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := AesECBDecrypt(aesECBEncrypt(t, tt.padded, key), key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AesECBDecrypt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("AesECBDecrypt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAesECBDecryptNotFullBlocks(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	for _, crypted := range [][]byte{nil, make([]byte, 15), make([]byte, 17)} {
+		_, err := AesECBDecrypt(crypted, key)
+		if err == nil {
+			t.Errorf("AesECBDecrypt(%d bytes) expected error", len(crypted))
+		}
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	proxies := []string{"10.0.0.0/8", "192.168.1.1"}
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		realIP     string
+		want       string
+	}{
+		{"untrusted remote ignores headers", "203.0.113.9:1234", "198.51.100.1", "198.51.100.2", "203.0.113.9"},
+		{"trusted remote without headers", "10.0.0.1:1234", "", "", "10.0.0.1"},
+		{"trusted remote uses real ip", "10.0.0.1:1234", "", "198.51.100.2", "198.51.100.2"},
+		{"trusted proxy chain", "10.0.0.1:1234", "198.51.100.1, 203.0.113.5, 192.168.1.1, 10.0.0.2", "", "203.0.113.5"},
+		{"spoofed leftmost entry", "10.0.0.1:1234", "1.2.3.4, 198.51.100.1", "", "198.51.100.1"},
+		{"all trusted chain", "10.0.0.1:1234", "10.0.0.3, 192.168.1.1", "", "10.0.0.3"},
+		{"garbage forwarded", "10.0.0.1:1234", "garbage", "", "10.0.0.1"},
+		{"garbage entries skipped", "10.0.0.1:1234", "198.51.100.1, garbage, 10.0.0.2", "", "198.51.100.1"},
+		{"garbage real ip", "10.0.0.1:1234", "", "not-an-ip", "10.0.0.1"},
+		{"ipv6 client", "10.0.0.1:1234", "2001:db8::1", "", "2001:db8::1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			if got := ClientIP(r, proxies...); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}